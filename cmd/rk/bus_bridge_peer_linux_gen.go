@@ -0,0 +1,37 @@
+//go:build linux
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// peerCred читает SO_PEERCRED другой стороны Unix-соединения; группа процессов — по её pid.
+func peerCred(conn net.Conn) (bridgePeer, error) {
+	uc, ok := conn.(*net.UnixConn)
+	if !ok {
+		return bridgePeer{}, errors.New("peer credentials: not a unix connection")
+	}
+	raw, err := uc.SyscallConn()
+	if err != nil {
+		return bridgePeer{}, fmt.Errorf("peer credentials: %w", err)
+	}
+	var cred *syscall.Ucred
+	var cerr error
+	if err := raw.Control(func(fd uintptr) {
+		cred, cerr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	}); err != nil {
+		return bridgePeer{}, fmt.Errorf("peer credentials: %w", err)
+	}
+	if cerr != nil {
+		return bridgePeer{}, fmt.Errorf("peer credentials: %w", cerr)
+	}
+	p := bridgePeer{UID: int(cred.Uid), PID: int(cred.Pid)}
+	if pgid, err := syscall.Getpgid(p.PID); err == nil {
+		p.PGID = pgid
+	}
+	return p, nil
+}
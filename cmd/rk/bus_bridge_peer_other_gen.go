@@ -0,0 +1,13 @@
+//go:build !linux
+
+package main
+
+import (
+	"errors"
+	"net"
+)
+
+// peerCred: без SO_PEERCRED подключение к мосту не проверить — оно отклоняется.
+func peerCred(net.Conn) (bridgePeer, error) {
+	return bridgePeer{}, errors.New("peer credentials are not supported on this platform")
+}
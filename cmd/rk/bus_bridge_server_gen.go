@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"example.com/ffp/platform/ports"
+	rt "example.com/ffp/platform/runtime"
+)
+
+// bridgeSocketAuto — значение bus.bridge_socket: сокет в приватном каталоге (0700)
+// в $XDG_RUNTIME_DIR, а без него — во временном каталоге.
+const bridgeSocketAuto = "auto"
+
+// bridgeSocketPath разворачивает bus.bridge_socket в путь сокета; cleanup удаляет созданный каталог.
+func bridgeSocketPath(cfg string) (path string, cleanup func(), err error) {
+	if cfg != bridgeSocketAuto {
+		return cfg, func() {}, nil
+	}
+	dir, err := os.MkdirTemp(os.Getenv("XDG_RUNTIME_DIR"), "rk-bus-")
+	if err != nil {
+		return "", nil, fmt.Errorf("bridge socket dir: %w", err)
+	}
+	return filepath.Join(dir, "bus.sock"), func() { _ = os.RemoveAll(dir) }, nil
+}
+
+// bridgePeer — процесс на другой стороне соединения моста (SO_PEERCRED).
+type bridgePeer struct {
+	UID, PID, PGID int
+}
+
+// belongsTo: соединение открыл сам процесс домена pid или процесс его группы.
+func (p bridgePeer) belongsTo(pid int) bool {
+	return pid > 0 && (p.PID == pid || p.PGID == pid)
+}
+
+// BusBridgeServer — сторона Root моста шины: принимает process-домены на Unix-сокете
+// и связывает их локальные шины с шиной rk по темам из их Exports/Imports.Events.
+type BusBridgeServer struct {
+	bus      ports.EventBus
+	reg      *DiscoveryRegistry
+	launcher *DomainKernelLauncher
+	guard    *rt.TopicGuard
+	nodeID   string
+	logger   ports.Logger
+
+	mu    sync.Mutex
+	conns map[string]net.Conn // kernel id → текущее соединение
+}
+
+// guard может быть nil; с ним контракт домена и отброшенные мостом темы видны в /admin/bus/contracts.
+func NewBusBridgeServer(bus ports.EventBus, reg *DiscoveryRegistry, launcher *DomainKernelLauncher, guard *rt.TopicGuard, nodeID string, logger ports.Logger) *BusBridgeServer {
+	if nodeID == "" {
+		nodeID = "rk"
+	}
+	return &BusBridgeServer{bus: bus, reg: reg, launcher: launcher, guard: guard, nodeID: nodeID, logger: logger, conns: make(map[string]net.Conn)}
+}
+
+// Serve слушает сокет до отмены ctx; оставшийся от прошлого запуска файл сокета удаляется.
+// Сокет доступен только владельцу (0600).
+func (s *BusBridgeServer) Serve(ctx context.Context, path string) error {
+	if fi, err := os.Stat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		_ = os.Remove(path)
+	}
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		_ = lis.Close()
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		_ = lis.Close()
+	}()
+	defer os.Remove(path)
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go s.handle(ctx, conn)
+	}
+}
+
+// handle принимает соединение только от процесса того же пользователя; id ядра из hello
+// сверяется с pid запущенного домена в link.
+func (s *BusBridgeServer) handle(ctx context.Context, conn net.Conn) {
+	peer, err := peerCred(conn)
+	if err == nil && peer.UID != os.Getuid() {
+		err = fmt.Errorf("peer uid %d, want %d", peer.UID, os.Getuid())
+	}
+	if err != nil {
+		_ = conn.Close()
+		s.log("WARN", "bus bridge rejected", map[string]any{"pid": peer.PID, "err": err.Error()})
+		return
+	}
+	kernel, err := rt.AcceptBusBridge(ctx, conn, func(kernel string) (rt.BridgeLink, error) {
+		link, err := s.link(kernel, peer)
+		if err == nil {
+			s.attach(kernel, conn)
+		}
+		return link, err
+	})
+	if kernel != "" {
+		s.detach(kernel, conn)
+	}
+	fields := map[string]any{"kernel": kernel}
+	if err != nil && !errors.Is(err, io.EOF) && ctx.Err() == nil {
+		fields["err"] = err.Error()
+		s.log("WARN", "bus bridge closed", fields)
+		return
+	}
+	s.log("INFO", "bus bridge closed", fields)
+}
+
+// link — параметры моста для ядра: только готовый process-домен, подключившийся из своего
+// процесса (или его группы); темы — из его handshake.
+func (s *BusBridgeServer) link(kernel string, peer bridgePeer) (rt.BridgeLink, error) {
+	pid := s.launcher.processPid(kernel)
+	if pid == 0 {
+		return rt.BridgeLink{}, fmt.Errorf("kernel %s is not a ready process domain", kernel)
+	}
+	if !peer.belongsTo(pid) {
+		return rt.BridgeLink{}, fmt.Errorf("peer pid %d is not process domain %s (pid %d)", peer.PID, kernel, pid)
+	}
+	var rec KernelRecord
+	for _, k := range s.reg.Kernels() {
+		if k.ID == kernel {
+			rec = k
+			break
+		}
+	}
+	exports, imports := rt.BridgeTopics(rec.Exports, rec.Imports)
+	s.log("INFO", "bus bridge connected", map[string]any{"kernel": kernel, "exports": exports, "imports": imports})
+	// к ребёнку уходят его imports, от него принимаются его exports
+	link := rt.BridgeLink{Bus: s.bus, Self: s.nodeID, Send: imports, Accept: exports, Logger: s.logger}
+	if s.guard != nil {
+		// мост пропускает только объявленное — вне зависимости от строгости
+		link.Bus = s.guard.View(kernel, func() ([]string, []string) { return exports, imports }, rt.TopicsEnforce)
+		link.Rejected = func(topic string) { s.guard.Record(kernel, "publish", topic) }
+	}
+	return link, nil
+}
+
+// attach запоминает соединение ядра; прежнее (например, от прошлой инкарнации) закрывается.
+func (s *BusBridgeServer) attach(kernel string, conn net.Conn) {
+	s.mu.Lock()
+	old := s.conns[kernel]
+	s.conns[kernel] = conn
+	s.mu.Unlock()
+	if old != nil {
+		_ = old.Close()
+	}
+}
+
+func (s *BusBridgeServer) detach(kernel string, conn net.Conn) {
+	s.mu.Lock()
+	if s.conns[kernel] == conn {
+		delete(s.conns, kernel)
+	}
+	s.mu.Unlock()
+}
+
+func (s *BusBridgeServer) log(level, msg string, fields map[string]any) {
+	if s.logger != nil {
+		s.logger.Log(context.Background(), level, msg, fields)
+	}
+}
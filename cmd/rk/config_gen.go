@@ -3,8 +3,12 @@ package main
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"example.com/ffp/platform/ports"
+	rt "example.com/ffp/platform/runtime"
 )
 
 type RootSection struct {
@@ -35,14 +39,121 @@ type TelemetryConfig struct {
 	Filters TelemetryFilters `yaml:"filters"`
 }
 
+// BusConfig — настройки шины событий rk.
+type BusConfig struct {
+	// Retained — шаблоны тем, хранящих последнее значение для новых подписчиков
+	// (telemetry.health.root помечена всегда).
+	Retained []string `yaml:"retained"`
+	// TopicContracts — строгость контрактов тем ядер по умолчанию: off | warn (по умолчанию) | enforce.
+	TopicContracts string `yaml:"topic_contracts"`
+	// BridgeSocket — Unix-сокет моста шины для process-доменов ("" — мост выключен,
+	// "auto" — в приватном каталоге rk).
+	BridgeSocket string `yaml:"bridge_socket"`
+}
+
 type DomainSpec struct {
 	ID           string          `yaml:"id"`
 	Mode         string          `yaml:"mode"`
 	Kind         string          `yaml:"kind"`
-	Entry        string          `yaml:"entry"`   // для remote
+	Entry        string          `yaml:"entry"`   // для remote: http(s)://host:port удалённого ядра
 	Command      string          `yaml:"command"` // для process
 	FeatureFlags map[string]bool `yaml:"feature_flags"`
 	Config       map[string]any  `yaml:"config"`
+	Hooks        HookTimeouts    `yaml:"hooks"` // лимиты hook-ов; пустые поля берутся из RootConfig.Hooks
+
+	// Параметры режима process.
+	Args         []string          `yaml:"args"`          // дополнительные аргументы к command
+	Env          map[string]string `yaml:"env"`           // переменные окружения дочернего процесса
+	HealthURL    string            `yaml:"health_url"`    // HTTP-проба готовности (опционально)
+	ReadyTimeout time.Duration     `yaml:"ready_timeout"` // ожидание готовности (по умолчанию 10s)
+	Handshake    string            `yaml:"handshake"`     // "" (READY/проба) | stdout | pipe — где ждать кадр hello
+	StopTimeout  time.Duration     `yaml:"stop_timeout"`  // grace между SIGTERM и SIGKILL (по умолчанию 5s)
+	Resources    map[string]any    `yaml:"resources"`     // лимиты как в Manifest.Resources: memory, cpu_time, open_files, processes
+
+	// Параметры режима remote.
+	PollInterval time.Duration `yaml:"poll_interval"` // период опроса entry (по умолчанию 5s)
+	FailAfter    time.Duration `yaml:"fail_after"`    // сколько быть degraded до failed (по умолчанию 30s)
+
+	// Надзор за process-доменом.
+	Restart       string        `yaml:"restart"`        // permanent (по умолчанию) | transient | temporary
+	MaxRestarts   int           `yaml:"max_restarts"`   // crash-loop: не больше N рестартов (по умолчанию 5)...
+	RestartWindow time.Duration `yaml:"restart_window"` // ...за окно T (по умолчанию 1m)
+	Backoff       BackoffConfig `yaml:"backoff"`
+	ProbeBackoff  BackoffConfig `yaml:"probe_backoff"` // паузы между HTTP-пробами готовности (по умолчанию 200ms)
+	// TopicContracts — строгость контракта тем inproc-домена: off | warn | enforce ("" — bus.topic_contracts).
+	TopicContracts string `yaml:"topic_contracts"`
+}
+
+// BackoffConfig — YAML-представление rt.BackoffSpec; нулевые поля заменяются значениями рантайма.
+type BackoffConfig struct {
+	Strategy string        `yaml:"strategy"` // exponential (по умолчанию) | decorrelated | constant | linear
+	Min      time.Duration `yaml:"min"`
+	Max      time.Duration `yaml:"max"`
+	Factor   float64       `yaml:"factor"`
+	Jitter   float64       `yaml:"jitter"`
+	Step     time.Duration `yaml:"step"` // для linear
+}
+
+func (c BackoffConfig) Spec() rt.BackoffSpec {
+	return rt.BackoffSpec{Strategy: c.Strategy, Min: c.Min, Max: c.Max, Factor: c.Factor, Jitter: c.Jitter, Step: c.Step}
+}
+
+// Backoff строит стратегию пауз; неизвестная strategy — ошибка.
+func (c BackoffConfig) Backoff() (rt.Backoff, error) {
+	return c.Spec().Build(nil)
+}
+
+// HookTimeouts — YAML-представление rt.HookTimeouts (0 — по умолчанию, отрицательное — без лимита).
+type HookTimeouts struct {
+	Load      time.Duration `yaml:"load"`
+	Init      time.Duration `yaml:"init"`
+	Configure time.Duration `yaml:"configure"`
+	Start     time.Duration `yaml:"start"`
+	Ready     time.Duration `yaml:"ready"`
+	Drain     time.Duration `yaml:"drain"`
+	Stop      time.Duration `yaml:"stop"`
+}
+
+// Merge дополняет незаданные лимиты значениями def.
+func (h HookTimeouts) Merge(def HookTimeouts) HookTimeouts {
+	pick := func(v, d time.Duration) time.Duration {
+		if v == 0 {
+			return d
+		}
+		return v
+	}
+	return HookTimeouts{
+		Load:      pick(h.Load, def.Load),
+		Init:      pick(h.Init, def.Init),
+		Configure: pick(h.Configure, def.Configure),
+		Start:     pick(h.Start, def.Start),
+		Ready:     pick(h.Ready, def.Ready),
+		Drain:     pick(h.Drain, def.Drain),
+		Stop:      pick(h.Stop, def.Stop),
+	}
+}
+
+func (h HookTimeouts) Timeouts() rt.HookTimeouts {
+	return rt.HookTimeouts{Load: h.Load, Init: h.Init, Configure: h.Configure, Start: h.Start, Ready: h.Ready, Drain: h.Drain, Stop: h.Stop}
+}
+
+// restartIntensity — лимит crash-loop домена с учётом значений по умолчанию.
+func (d DomainSpec) restartIntensity() rt.RestartIntensity {
+	limit := rt.RestartIntensity{Max: d.MaxRestarts, Window: d.RestartWindow}
+	if limit.Max <= 0 {
+		limit.Max = 5
+	}
+	if limit.Window <= 0 {
+		limit.Window = time.Minute
+	}
+	return limit
+}
+
+// ResourcesConfig — как rk ограничивает ресурсы process-доменов.
+type ResourcesConfig struct {
+	// CgroupParent — делегированная rk cgroup v2 без своих процессов (путь или путь от /sys/fs/cgroup);
+	// в ней для каждого процесса создаётся группа rk-*. Пусто — memory/processes через rlimit.
+	CgroupParent string `yaml:"cgroup_parent"`
 }
 
 type RootConfig struct {
@@ -50,6 +161,9 @@ type RootConfig struct {
 	Admin     AdminConfig     `yaml:"admin"`
 	Discovery DiscoveryConfig `yaml:"discovery"`
 	Telemetry TelemetryConfig `yaml:"telemetry"`
+	Bus       BusConfig       `yaml:"bus"`
+	Resources ResourcesConfig `yaml:"resources"`
+	Hooks     HookTimeouts    `yaml:"hooks"` // лимиты hook-ов доменов по умолчанию
 	Domains   []DomainSpec    `yaml:"domains"`
 }
 
@@ -85,5 +199,26 @@ func (c RootConfig) Validate() error {
 	if c.Admin.GRPCAddr == "" {
 		return fmt.Errorf("admin.grpc_addr is required")
 	}
+	for _, p := range c.Bus.Retained {
+		if err := ports.ValidateTopicPattern(p); err != nil {
+			return fmt.Errorf("bus.retained: %w", err)
+		}
+	}
+	if _, err := rt.ParseTopicStrictness(c.Bus.TopicContracts); err != nil {
+		return fmt.Errorf("bus.topic_contracts: %w", err)
+	}
+	for _, d := range c.Domains {
+		if d.TopicContracts != "" {
+			if _, err := rt.ParseTopicStrictness(d.TopicContracts); err != nil {
+				return fmt.Errorf("domain %s: topic_contracts: %w", d.ID, err)
+			}
+		}
+		if _, err := d.Backoff.Backoff(); err != nil {
+			return fmt.Errorf("domain %s: %w", d.ID, err)
+		}
+		if _, err := d.ProbeBackoff.Backoff(); err != nil {
+			return fmt.Errorf("domain %s: probe_backoff: %w", d.ID, err)
+		}
+	}
 	return nil
 }
@@ -6,16 +6,33 @@ import (
 	"time"
 
 	"example.com/ffp/platform/contracts"
+	rt "example.com/ffp/platform/runtime"
 )
 
 type DegradationPolicy struct {
 	reg      *DiscoveryRegistry
 	mu       sync.Mutex
 	snapshot map[string]*contracts.Exports // оригинальные экспорты
+	clock    rt.Clock
 }
 
-func NewDegradationPolicy(reg *DiscoveryRegistry) *DegradationPolicy {
-	return &DegradationPolicy{reg: reg, snapshot: make(map[string]*contracts.Exports)}
+type DegradationOption func(*DegradationPolicy)
+
+// WithDegradationClock задаёт часы периода проверки (по умолчанию rt.SystemClock).
+func WithDegradationClock(c rt.Clock) DegradationOption {
+	return func(p *DegradationPolicy) {
+		if c != nil {
+			p.clock = c
+		}
+	}
+}
+
+func NewDegradationPolicy(reg *DiscoveryRegistry, opts ...DegradationOption) *DegradationPolicy {
+	p := &DegradationPolicy{reg: reg, snapshot: make(map[string]*contracts.Exports), clock: rt.SystemClock}
+	for _, o := range opts {
+		o(p)
+	}
+	return p
 }
 
 func (p *DegradationPolicy) copyExports(ex *contracts.Exports) *contracts.Exports {
@@ -45,14 +62,14 @@ func (p *DegradationPolicy) Run(ctx context.Context, interval time.Duration) {
 	if interval <= 0 {
 		interval = time.Second
 	}
-	t := time.NewTicker(interval)
+	t := p.clock.NewTicker(interval)
 	defer t.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-t.C:
+		case <-t.C():
 			list := p.reg.List()
 			for _, rec := range list {
 				switch rec.Health.Status {
@@ -1,6 +1,27 @@
 package main
 
-import "example.com/ffp/platform/contracts"
+import (
+	"time"
+
+	"example.com/ffp/platform/contracts"
+	rt "example.com/ffp/platform/runtime"
+)
+
+// KernelResources — лимиты ресурсов ядра и последний снимок их потребления.
+type KernelResources struct {
+	Limits      rt.ResourceLimits    `json:"limits"`
+	Enforcement *rt.LimitEnforcement `json:"enforcement,omitempty"` // cgroup или откат на rlimit
+	Usage       *rt.ResourceUsage    `json:"usage,omitempty"`
+}
+
+func kernelResources(runner *rt.ProcessRunner, u *rt.ResourceUsage) *KernelResources {
+	res := &KernelResources{Limits: runner.Limits(), Usage: u}
+	if !res.Limits.IsZero() {
+		enf := runner.Enforcement()
+		res.Enforcement = &enf
+	}
+	return res
+}
 
 func (r *DiscoveryRegistry) SetExports(id string, ex *contracts.Exports) {
 	r.mu.Lock()
@@ -9,3 +30,28 @@ func (r *DiscoveryRegistry) SetExports(id string, ex *contracts.Exports) {
 	}
 	r.mu.Unlock()
 }
+
+func (r *DiscoveryRegistry) SetImports(id string, im *contracts.Imports) {
+	r.mu.Lock()
+	if rec, ok := r.kernels[id]; ok {
+		rec.Imports = im
+	}
+	r.mu.Unlock()
+}
+
+func (r *DiscoveryRegistry) SetManifest(id string, m contracts.Manifest) {
+	r.mu.Lock()
+	if rec, ok := r.kernels[id]; ok {
+		rec.Manifest = m
+		rec.UpdatedAt = time.Now()
+	}
+	r.mu.Unlock()
+}
+
+func (r *DiscoveryRegistry) SetResources(id string, res *KernelResources) {
+	r.mu.Lock()
+	if rec, ok := r.kernels[id]; ok {
+		rec.Resources = res
+	}
+	r.mu.Unlock()
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"example.com/ffp/platform/contracts"
+)
+
+// ReportFunction регистрирует или обновляет function-ядро домена parent (rt.FunctionReporter).
+func (r *DiscoveryRegistry) ReportFunction(parent string, m contracts.Manifest, h contracts.Health) {
+	now := time.Now()
+	if h.Since.IsZero() {
+		h.Since = now
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec, ok := r.kernels[m.KernelID]
+	if !ok {
+		rec = &KernelRecord{ID: m.KernelID, RegisteredAt: now}
+		r.kernels[m.KernelID] = rec
+	}
+	rec.Scope = contracts.FunctionScope
+	rec.Parent = parent
+	rec.Manifest = m
+	rec.Health = h
+	rec.UpdatedAt = now
+}
+
+// RemoveFunction удаляет запись function-ядра (rt.FunctionReporter).
+func (r *DiscoveryRegistry) RemoveFunction(id string) { r.Unregister(id) }
+
+// UnregisterChildren удаляет записи всех вложенных ядер parent (id вида "<parent>/...").
+func (r *DiscoveryRegistry) UnregisterChildren(parent string) {
+	prefix := parent + "/"
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, rec := range r.kernels {
+		if rec.Parent == parent || strings.HasPrefix(id, prefix) {
+			delete(r.kernels, id)
+		}
+	}
+}
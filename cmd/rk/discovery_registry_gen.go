@@ -11,9 +11,12 @@ import (
 type KernelRecord struct {
 	ID           string             `json:"id"`
 	Scope        contracts.Scope    `json:"scope"`
+	Parent       string             `json:"parent,omitempty"` // id родительского ядра (для function-ядер)
 	Manifest     contracts.Manifest `json:"manifest"`
 	Health       contracts.Health   `json:"health"`
 	Exports      *contracts.Exports `json:"exports,omitempty"`
+	Imports      *contracts.Imports `json:"imports,omitempty"`
+	Resources    *KernelResources   `json:"resources,omitempty"`
 	RegisteredAt time.Time          `json:"registered_at"`
 	UpdatedAt    time.Time          `json:"updated_at"`
 }
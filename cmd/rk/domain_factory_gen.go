@@ -43,10 +43,11 @@ func LaunchDomainWithFactory(
 		ports.WithEventBus(bus),
 		ports.WithRPC(rpc),
 		ports.WithConfig(spec.Config),
+		rt.WithFunctionReporter(reg),
 	)
 
 	kernel := f(spec.ID)
-	fsm := rt.NewFSM(kernel, host)
+	fsm := rt.NewFSM(kernel, host, rt.WithHookTimeouts(spec.Hooks.Timeouts()))
 	if err := fsm.Run(ctx, spec.Config); err != nil {
 		return true, fmt.Errorf("run FSM: %w", err)
 	}
@@ -71,7 +72,7 @@ func LaunchDomainWithFactory(
 		Scope:        contracts.DomainScope,
 		Manifest:     kernel.Manifest(),
 		Exports:      &ex,
-		Health:       kernel.Health(),
+		Health:       fsm.Health(),
 		RegisteredAt: time.Now(),
 	}
 	reg.Register(rec)
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"example.com/ffp/platform/ports"
 )
@@ -12,12 +13,47 @@ type DomainKernelLauncher struct {
 	bus    ports.EventBus
 	logger ports.Logger
 	rpc    ports.RPC
+	hub    *LogHub
+
+	busSocket    string // сокет моста шины для process-доменов ("" — мост выключен)
+	cgroupParent string // делегированная cgroup v2 для лимитов process-доменов
+
+	mu      sync.Mutex
+	procs   map[string]*processDomain
+	remotes map[string]*remoteDomain
 }
 
 func NewDomainKernelLauncher(reg *DiscoveryRegistry, bus ports.EventBus, logger ports.Logger, rpc ports.RPC) *DomainKernelLauncher {
-	return &DomainKernelLauncher{reg: reg, bus: bus, logger: logger, rpc: rpc}
+	return &DomainKernelLauncher{reg: reg, bus: bus, logger: logger, rpc: rpc, procs: make(map[string]*processDomain), remotes: make(map[string]*remoteDomain)}
+}
+
+// SetLogHub задаёт хаб, в который пересылаются логи process-доменов.
+func (l *DomainKernelLauncher) SetLogHub(h *LogHub) {
+	l.hub = h
+}
+
+// SetBusSocket задаёт сокет моста шины: process-домены получают его в RK_BUS_SOCKET.
+func (l *DomainKernelLauncher) SetBusSocket(path string) {
+	l.busSocket = path
+}
+
+// SetCgroupParent задаёт делегированную cgroup v2, в которой process-домены получают свои группы.
+func (l *DomainKernelLauncher) SetCgroupParent(dir string) {
+	l.cgroupParent = dir
 }
 
 func (l *DomainKernelLauncher) Launch(ctx context.Context, spec DomainSpec) error {
-	return fmt.Errorf("launch mode %s not implemented", spec.Mode)
+	switch spec.Mode {
+	case "process":
+		return l.launchProcess(ctx, spec)
+	case "remote":
+		return l.launchRemote(ctx, spec)
+	default:
+		return fmt.Errorf("launch mode %s not implemented", spec.Mode)
+	}
+}
+
+// Stop останавливает домен, запущенный лаунчером. false — если такого домена нет.
+func (l *DomainKernelLauncher) Stop(id string) bool {
+	return l.stopProcess(id) || l.stopRemote(id)
 }
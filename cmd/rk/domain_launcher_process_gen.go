@@ -0,0 +1,495 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"example.com/ffp/platform/contracts"
+	rt "example.com/ffp/platform/runtime"
+	"example.com/ffp/platform/telemetry"
+)
+
+const (
+	// processStopTimeout — grace по умолчанию между SIGTERM и SIGKILL группе процесса.
+	processStopTimeout = 5 * time.Second
+	// processUsageInterval — как часто снимать потребление ресурсов для /admin/kernels.
+	processUsageInterval = 5 * time.Second
+)
+
+var errDomainStopping = errors.New("domain is stopping")
+
+// processDomain — доменное ядро, запущенное отдельным OS-процессом под надзором Root.
+// Каждый (пере)запуск создаёт новый ProcessRunner — "инкарнацию" домена.
+type processDomain struct {
+	spec    DomainSpec
+	policy  rt.RestartPolicy
+	backoff rt.Backoff
+	probe   rt.Backoff // nil — пауза пробы по умолчанию
+	limit   rt.RestartIntensity
+	tracker *rt.RestartTracker
+	limits  rt.ResourceLimits // из DomainSpec.Resources
+	ctx     context.Context   // живёт до остановки домена
+	cancel  context.CancelFunc
+
+	mu         sync.Mutex
+	runner     *rt.ProcessRunner // текущая инкарнация
+	startedAt  time.Time
+	attempts   int           // подряд идущие рестарты (для backoff)
+	lastSleep  time.Duration // предыдущая пауза (для decorrelated)
+	exceeded   string        // превышенный лимит ресурсов текущей инкарнации
+	ready      bool          // текущая инкарнация прошла WaitReady/handshake
+	registered bool          // запись уже есть в DiscoveryRegistry
+	stopping   bool          // остановка инициирована Root-ядром
+}
+
+// launchProcess запускает домен в режиме process: spawn → WaitReady → регистрация в discovery,
+// дальше процесс живёт под надзором (RestartPolicy + Backoff + лимит crash-loop).
+func (l *DomainKernelLauncher) launchProcess(ctx context.Context, spec DomainSpec) error {
+	if len(strings.Fields(spec.Command)) == 0 {
+		return fmt.Errorf("domain %s: command is required for mode process", spec.ID)
+	}
+	switch spec.Handshake {
+	case "", "stdout", "pipe":
+	default:
+		return fmt.Errorf("domain %s: unknown handshake %q (want stdout|pipe)", spec.ID, spec.Handshake)
+	}
+	policy, err := rt.ParseRestartPolicy(spec.Restart)
+	if err != nil {
+		return fmt.Errorf("domain %s: %w", spec.ID, err)
+	}
+	limits, err := rt.ParseResourceLimits(spec.Resources)
+	if err != nil {
+		return fmt.Errorf("domain %s: %w", spec.ID, err)
+	}
+	backoff, err := spec.Backoff.Backoff()
+	if err != nil {
+		return fmt.Errorf("domain %s: %w", spec.ID, err)
+	}
+	var probe rt.Backoff
+	if spec.ProbeBackoff != (BackoffConfig{}) {
+		if probe, err = spec.ProbeBackoff.Backoff(); err != nil {
+			return fmt.Errorf("domain %s: probe_backoff: %w", spec.ID, err)
+		}
+	}
+	l.mu.Lock()
+	_, exists := l.procs[spec.ID]
+	l.mu.Unlock()
+	if exists {
+		return fmt.Errorf("domain %s: process already running", spec.ID)
+	}
+
+	limit := spec.restartIntensity()
+	pctx, cancel := context.WithCancel(ctx)
+	pd := &processDomain{
+		spec:    spec,
+		policy:  policy,
+		backoff: backoff,
+		probe:   probe,
+		limit:   limit,
+		tracker: rt.NewRestartTracker(limit),
+		limits:  limits,
+		ctx:     pctx,
+		cancel:  cancel,
+	}
+	if err := l.startIncarnation(pd); err != nil {
+		cancel()
+		return err
+	}
+
+	l.mu.Lock()
+	l.procs[spec.ID] = pd
+	l.mu.Unlock()
+	go l.supervise(pd)
+	return nil
+}
+
+// processOptions собирает опции раннера из спецификации домена.
+func (l *DomainKernelLauncher) processOptions(pd *processDomain) []rt.PROption {
+	spec := pd.spec
+	env := map[string]string{
+		"RK_KERNEL_ID": spec.ID,
+		"RK_SCOPE":     string(contracts.DomainScope),
+	}
+	if len(spec.Config) > 0 {
+		if b, err := json.Marshal(spec.Config); err == nil {
+			env["RK_CONFIG"] = string(b)
+		}
+	}
+	if l.busSocket != "" {
+		env[contracts.EnvBusSocket] = l.busSocket
+	}
+	for k, v := range spec.Env {
+		env[k] = v
+	}
+	opts := []rt.PROption{
+		rt.WithEnvMap(env),
+		rt.WithProcEventHook(func(ev rt.ProcEvent) { l.onProcEvent(pd, ev) }),
+	}
+	if spec.HealthURL != "" {
+		opts = append(opts, rt.WithHealthHTTP(spec.HealthURL))
+	}
+	if pd.probe != nil {
+		opts = append(opts, rt.WithProbeBackoff(pd.probe))
+	}
+	if !pd.limits.IsZero() {
+		opts = append(opts, rt.WithResourceLimits(pd.limits), rt.WithCgroupParent(l.cgroupParent))
+	}
+	switch spec.Handshake {
+	case "stdout":
+		opts = append(opts, rt.WithHandshakeRequired())
+	case "pipe":
+		opts = append(opts, rt.WithHandshakeRequired(), rt.WithControlPipe())
+	}
+	return opts
+}
+
+// startIncarnation запускает очередной процесс домена и дожидается его готовности.
+// При неудаче запущенный процесс останавливается.
+func (l *DomainKernelLauncher) startIncarnation(pd *processDomain) error {
+	argv := strings.Fields(pd.spec.Command)
+	args := append(append([]string(nil), argv[1:]...), pd.spec.Args...)
+	runner := rt.NewProcessRunner(argv[0], args, l.processOptions(pd)...)
+
+	pd.mu.Lock()
+	if pd.stopping {
+		pd.mu.Unlock()
+		return errDomainStopping
+	}
+	pd.runner, pd.ready, pd.exceeded, pd.startedAt = runner, false, "", time.Now()
+	pd.mu.Unlock()
+
+	if err := runner.Start(pd.ctx); err != nil {
+		return fmt.Errorf("start process: %w", err)
+	}
+	settled := make(chan struct{})
+	defer close(settled)
+	go l.forwardLogs(pd, runner)
+	go l.consumeControl(pd, runner, settled)
+
+	if err := runner.WaitReady(pd.ctx, pd.spec.ReadyTimeout); err != nil {
+		l.terminate(pd, runner)
+		return err
+	}
+
+	rec := KernelRecord{
+		ID:       pd.spec.ID,
+		Scope:    contracts.DomainScope,
+		Manifest: contracts.Manifest{KernelID: pd.spec.ID, Scope: contracts.DomainScope},
+		Health:   contracts.Health{Status: contracts.HealthReady, Since: time.Now()},
+	}
+	if hello, ok := runner.Handshake(); ok {
+		if err := checkHello(pd.spec, hello); err != nil {
+			l.terminate(pd, runner)
+			return fmt.Errorf("handshake: %w", err)
+		}
+		declared, err := rt.ParseResourceLimits(hello.Manifest.Resources)
+		if err != nil {
+			l.terminate(pd, runner)
+			return fmt.Errorf("handshake: manifest %w", err)
+		}
+		if tight := runner.Limits().Tighten(declared); tight != runner.Limits() {
+			if err := runner.ApplyLimits(tight); err != nil {
+				l.log("WARN", "process domain limits not fully applied", map[string]any{"id": pd.spec.ID, "err": err.Error()})
+			}
+		}
+		rec.Manifest = *hello.Manifest
+		rec.Exports = hello.Exports
+		rec.Imports = hello.Imports
+	}
+	rec.Resources = kernelResources(runner, nil)
+	if enf := runner.Enforcement(); len(enf.Fallback) > 0 {
+		l.log("WARN", "process domain limits fall back to rlimit", map[string]any{
+			"id": pd.spec.ID, "fallback": enf.Fallback, "cgroup_error": enf.CgroupError,
+		})
+	}
+
+	pd.mu.Lock()
+	defer pd.mu.Unlock()
+	select {
+	case <-runner.Done():
+		// процесс успел завершиться между READY и регистрацией
+		code, _ := runner.ExitStatus()
+		return fmt.Errorf("process exited with code %d right after ready", code)
+	default:
+	}
+	if pd.stopping {
+		return errDomainStopping
+	}
+	pd.ready = true
+	pd.registered = true
+	l.reg.Register(rec)
+	go l.sampleUsage(pd, runner)
+	return nil
+}
+
+// processPid — pid текущей инкарнации process-домена id, прошедшей handshake (0 — не готов или не запущен).
+func (l *DomainKernelLauncher) processPid(id string) int {
+	l.mu.Lock()
+	pd, ok := l.procs[id]
+	l.mu.Unlock()
+	if !ok {
+		return 0
+	}
+	pd.mu.Lock()
+	defer pd.mu.Unlock()
+	if !pd.ready || pd.stopping || pd.runner == nil {
+		return 0
+	}
+	return pd.runner.Pid()
+}
+
+// sampleUsage периодически снимает потребление ресурсов инкарнацией и кладёт его в discovery.
+func (l *DomainKernelLauncher) sampleUsage(pd *processDomain, runner *rt.ProcessRunner) {
+	t := time.NewTicker(processUsageInterval)
+	defer t.Stop()
+	for {
+		u, err := runner.Usage()
+		if err == nil {
+			pd.mu.Lock()
+			current := pd.runner == runner && !pd.stopping
+			pd.mu.Unlock()
+			if !current {
+				return
+			}
+			l.reg.SetResources(pd.spec.ID, kernelResources(runner, &u))
+		}
+		select {
+		case <-t.C:
+		case <-runner.Done():
+			return
+		}
+	}
+}
+
+// supervise ждёт выхода текущей инкарнации и применяет политику рестартов.
+func (l *DomainKernelLauncher) supervise(pd *processDomain) {
+	for {
+		pd.mu.Lock()
+		runner := pd.runner
+		pd.mu.Unlock()
+
+		select {
+		case <-runner.Done():
+		case <-pd.ctx.Done():
+			return
+		}
+		exit := runner.Exit()
+		if !l.restartProcess(pd, exit.Code != 0, exit.String()) {
+			return
+		}
+	}
+}
+
+// restartProcess перезапускает домен после выхода процесса, пока это разрешает политика.
+// Возвращает false, если надзор окончен: остановка, политика без рестарта или crash-loop.
+// cause — причина последнего сбоя, попадает в health.reason.
+func (l *DomainKernelLauncher) restartProcess(pd *processDomain, failed bool, cause string) bool {
+	id := pd.spec.ID
+	for {
+		pd.mu.Lock()
+		stopping := pd.stopping
+		if pd.limit.Window > 0 && time.Since(pd.startedAt) > pd.limit.Window {
+			pd.attempts, pd.lastSleep = 0, 0 // процесс проработал дольше окна — считаем его стабильным
+		}
+		pd.mu.Unlock()
+		if stopping {
+			return false
+		}
+
+		if !pd.policy.ShouldRestart(failed) {
+			if !failed {
+				l.reg.UpdateHealth(id, contracts.Health{Status: contracts.HealthStopped, Reason: "process exited", Since: time.Now()})
+			}
+			return false
+		}
+		if pd.tracker.Record(time.Now()) {
+			reason := fmt.Sprintf("crash loop: more than %d restarts within %s (last: %s), waiting for operator restart", pd.limit.Max, pd.limit.Window, cause)
+			l.reg.UpdateHealth(id, contracts.Health{Status: contracts.HealthFailed, Reason: reason, Since: time.Now()})
+			l.log("ERROR", "process domain crash loop", map[string]any{"id": id, "max_restarts": pd.limit.Max, "window": pd.limit.Window.String()})
+			return false
+		}
+
+		pd.mu.Lock()
+		pd.attempts++
+		attempt := pd.attempts
+		sleep := pd.backoff.Next(attempt, pd.lastSleep)
+		pd.lastSleep = sleep
+		pd.mu.Unlock()
+		l.reg.UpdateHealth(id, contracts.Health{
+			Status: contracts.HealthDegraded,
+			Reason: fmt.Sprintf("restarting in %s (attempt %d): %s", sleep, attempt, cause),
+			Since:  time.Now(),
+		})
+		l.log("WARN", "process domain restarting", map[string]any{"id": id, "attempt": attempt, "sleep": sleep.String()})
+
+		select {
+		case <-time.After(sleep):
+		case <-pd.ctx.Done():
+			return false
+		}
+		err := l.startIncarnation(pd)
+		if err == nil {
+			l.log("INFO", "process domain restarted", map[string]any{"id": id, "attempt": attempt})
+			return true
+		}
+		if errors.Is(err, errDomainStopping) {
+			return false
+		}
+		l.log("WARN", "process domain restart failed", map[string]any{"id": id, "attempt": attempt, "err": err.Error()})
+		failed, cause = true, err.Error() // неудачный запуск — ещё один сбой
+	}
+}
+
+// forwardLogs превращает stdout/stderr процесса в LogRecordV2 и публикует их через LogHub.
+// Канал вычитывается до конца в любом случае, чтобы процесс не блокировался на записи.
+func (l *DomainKernelLauncher) forwardLogs(pd *processDomain, runner *rt.ProcessRunner) {
+	for line := range runner.Logs() {
+		if l.hub == nil {
+			continue
+		}
+		rec := telemetry.ParseLogLine(pd.spec.ID, string(contracts.DomainScope), line.Origin, line.Line, line.Time)
+		l.hub.Publish(context.Background(), rec)
+	}
+}
+
+// checkHello валидирует handshake и сверяет его с конфигом домена.
+func checkHello(spec DomainSpec, hello contracts.ControlMessage) error {
+	if err := hello.Validate(); err != nil {
+		return err
+	}
+	if hello.Manifest.KernelID != spec.ID {
+		return fmt.Errorf("kernel_id %q does not match domain id %q", hello.Manifest.KernelID, spec.ID)
+	}
+	if hello.Manifest.Scope != contracts.DomainScope {
+		return fmt.Errorf("scope %q, want %q", hello.Manifest.Scope, contracts.DomainScope)
+	}
+	return nil
+}
+
+// consumeControl применяет кадры управляющего канала после handshake: health и повторные hello.
+// До завершения запуска кадры копятся в буфере раннера (лишние отбрасываются); если инкарнация не стала готовой — просто вычитываются.
+func (l *DomainKernelLauncher) consumeControl(pd *processDomain, runner *rt.ProcessRunner, settled <-chan struct{}) {
+	<-settled
+	for msg := range runner.Control() {
+		var err error
+		if msg.Type == contracts.ControlHello {
+			err = checkHello(pd.spec, msg)
+		} else {
+			err = msg.Validate()
+		}
+		if err != nil {
+			l.log("WARN", "invalid control message", map[string]any{"id": pd.spec.ID, "type": string(msg.Type), "err": err.Error()})
+			continue
+		}
+
+		pd.mu.Lock()
+		if pd.stopping || pd.runner != runner || !pd.ready {
+			pd.mu.Unlock()
+			continue
+		}
+		switch msg.Type {
+		case contracts.ControlHealth:
+			l.reg.UpdateHealth(pd.spec.ID, *msg.Health)
+		case contracts.ControlHello:
+			l.reg.SetManifest(pd.spec.ID, *msg.Manifest)
+			l.reg.SetExports(pd.spec.ID, msg.Exports)
+			l.reg.SetImports(pd.spec.ID, msg.Imports)
+		}
+		pd.mu.Unlock()
+	}
+}
+
+// onProcEvent переводит события процесса в health записи discovery.
+func (l *DomainKernelLauncher) onProcEvent(pd *processDomain, ev rt.ProcEvent) {
+	fields := map[string]any{"id": pd.spec.ID, "event": string(ev.Type)}
+	if ev.Note != "" {
+		fields["note"] = ev.Note
+	}
+	if ev.Err != nil {
+		fields["err"] = ev.Err.Error()
+	}
+
+	level := "INFO"
+	switch ev.Type {
+	case rt.ProcLimitExceeded:
+		pd.mu.Lock()
+		pd.exceeded = ev.Note
+		pd.mu.Unlock()
+	case rt.ProcExit:
+		fields["exit_code"] = ev.ExitCode
+
+		pd.mu.Lock()
+		if !pd.stopping {
+			level = "WARN"
+			if pd.registered {
+				reason := fmt.Sprintf("process exited with code %d", ev.ExitCode)
+				switch {
+				case pd.exceeded != "":
+					reason = "process killed: " + pd.exceeded
+				case ev.Note != "":
+					reason = "process killed by " + ev.Note
+				}
+				l.reg.UpdateHealth(pd.spec.ID, contracts.Health{Status: contracts.HealthFailed, Reason: reason, Since: ev.Time})
+			}
+		}
+		pd.mu.Unlock()
+	}
+	switch ev.Type {
+	case rt.ProcProbeKO:
+		level = "DEBUG"
+	case rt.ProcControlError, rt.ProcGraceExpired, rt.ProcLimitExceeded:
+		level = "WARN"
+	case rt.ProcLimits:
+		if ev.Err != nil {
+			level = "WARN"
+		}
+	}
+	l.log(level, "process domain event", fields)
+}
+
+// stopProcess мягко останавливает процесс домена, прекращает надзор и снимает домен с регистрации.
+func (l *DomainKernelLauncher) stopProcess(id string) bool {
+	l.mu.Lock()
+	pd, ok := l.procs[id]
+	delete(l.procs, id)
+	l.mu.Unlock()
+	if !ok {
+		return false
+	}
+	pd.mu.Lock()
+	pd.stopping = true
+	runner := pd.runner
+	pd.mu.Unlock()
+
+	exit, err := l.terminate(pd, runner)
+	pd.cancel()
+	l.reg.Unregister(id)
+	fields := map[string]any{"id": id, "exit": exit.String(), "killed": exit.Killed}
+	if err != nil {
+		fields["err"] = err.Error()
+		l.log("WARN", "process domain stopped", fields)
+	} else {
+		l.log("INFO", "process domain stopped", fields)
+	}
+	return true
+}
+
+// terminate останавливает группу процесса: SIGTERM, stop_timeout на выход, затем SIGKILL.
+func (l *DomainKernelLauncher) terminate(pd *processDomain, runner *rt.ProcessRunner) (rt.ExitInfo, error) {
+	grace := pd.spec.StopTimeout
+	if grace <= 0 {
+		grace = processStopTimeout
+	}
+	return runner.Shutdown(context.Background(), grace)
+}
+
+func (l *DomainKernelLauncher) log(level, msg string, fields map[string]any) {
+	if l.logger != nil {
+		l.logger.Log(context.Background(), level, msg, fields)
+	}
+}
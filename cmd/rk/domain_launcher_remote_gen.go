@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"example.com/ffp/platform/contracts"
+)
+
+const (
+	remotePollInterval   = 5 * time.Second
+	remoteFailAfter      = 30 * time.Second
+	remoteRequestTimeout = 5 * time.Second
+)
+
+// remoteDomain — доменное ядро, работающее на другом хосте; Root только опрашивает его entry.
+type remoteDomain struct {
+	spec      DomainSpec
+	url       string
+	interval  time.Duration
+	failAfter time.Duration
+	client    *http.Client
+	cancel    context.CancelFunc
+	done      chan struct{}
+
+	// состояние опроса (только в горутине pollRemote)
+	registered bool
+	lastOK     time.Time
+	failing    time.Time // начало текущей серии неудачных опросов
+}
+
+// launchRemote подключает удалённое ядро: GET entry + contracts.RemoteKernelPath отдаёт hello
+// с manifest/exports/imports и health. Недоступность при запуске не ошибка — домен
+// регистрируется как degraded и опрашивается дальше.
+func (l *DomainKernelLauncher) launchRemote(ctx context.Context, spec DomainSpec) error {
+	u, err := remoteURL(spec.Entry)
+	if err != nil {
+		return fmt.Errorf("domain %s: %w", spec.ID, err)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, exists := l.remotes[spec.ID]; exists {
+		return fmt.Errorf("domain %s: remote already attached", spec.ID)
+	}
+
+	rd := &remoteDomain{
+		spec:      spec,
+		url:       u,
+		interval:  spec.PollInterval,
+		failAfter: spec.FailAfter,
+		client:    &http.Client{Timeout: remoteRequestTimeout},
+		done:      make(chan struct{}),
+	}
+	if rd.interval <= 0 {
+		rd.interval = remotePollInterval
+	}
+	if rd.failAfter <= 0 {
+		rd.failAfter = remoteFailAfter
+	}
+	rctx, cancel := context.WithCancel(ctx)
+	rd.cancel = cancel
+	rd.failing = time.Now()
+	// до первого hello запись — заглушка домена: иначе UpdateHealth неудачного опроса
+	// создал бы её без scope
+	l.reg.Register(KernelRecord{
+		ID:       spec.ID,
+		Scope:    contracts.DomainScope,
+		Manifest: contracts.Manifest{KernelID: spec.ID, Scope: contracts.DomainScope},
+		Health:   contracts.Health{Status: contracts.HealthDegraded, Reason: "waiting for first remote poll", Since: rd.failing},
+	})
+	l.remotes[spec.ID] = rd
+	go l.pollRemote(rctx, rd)
+	return nil
+}
+
+func remoteURL(entry string) (string, error) {
+	if entry == "" {
+		return "", fmt.Errorf("entry is required for mode remote")
+	}
+	u, err := url.Parse(entry)
+	if err != nil {
+		return "", fmt.Errorf("entry: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("entry %q: want http(s)://host[:port]", entry)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + contracts.RemoteKernelPath
+	return u.String(), nil
+}
+
+// pollRemote опрашивает удалённое ядро до остановки домена.
+func (l *DomainKernelLauncher) pollRemote(ctx context.Context, rd *remoteDomain) {
+	defer close(rd.done)
+	t := time.NewTicker(rd.interval)
+	defer t.Stop()
+	for {
+		l.refreshRemote(ctx, rd)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// refreshRemote выполняет один опрос и переносит результат в discovery.
+func (l *DomainKernelLauncher) refreshRemote(ctx context.Context, rd *remoteDomain) {
+	id := rd.spec.ID
+	hello, err := fetchRemote(ctx, rd.client, rd.url)
+	if err == nil {
+		err = checkHello(rd.spec, hello)
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	now := time.Now()
+	if err != nil {
+		if rd.failing.IsZero() {
+			rd.failing = now
+		}
+		status, reason := contracts.HealthDegraded, "remote unreachable: "+err.Error()
+		if now.Sub(rd.failing) >= rd.failAfter {
+			status = contracts.HealthFailed
+			reason = fmt.Sprintf("remote unreachable for %s: %s", now.Sub(rd.failing).Round(100*time.Millisecond), err)
+		}
+		l.reg.UpdateHealth(id, contracts.Health{Status: status, Reason: reason, Since: rd.failing})
+		l.log("WARN", "remote domain poll failed", map[string]any{"id": id, "url": rd.url, "status": string(status), "err": err.Error()})
+		return
+	}
+
+	health := contracts.Health{Status: contracts.HealthReady, Since: now}
+	if hello.Health != nil && hello.Health.Status.Valid() {
+		health = *hello.Health
+	}
+	if !rd.registered {
+		l.reg.Register(KernelRecord{
+			ID:       id,
+			Scope:    contracts.DomainScope,
+			Manifest: *hello.Manifest,
+			Exports:  hello.Exports,
+			Imports:  hello.Imports,
+			Health:   health,
+		})
+		rd.registered = true
+		l.log("INFO", "remote domain attached", map[string]any{"id": id, "url": rd.url, "version": hello.Manifest.Version})
+	} else {
+		l.reg.SetManifest(id, *hello.Manifest)
+		l.reg.SetExports(id, hello.Exports)
+		l.reg.SetImports(id, hello.Imports)
+		l.reg.UpdateHealth(id, health)
+	}
+	if !rd.failing.IsZero() && !rd.lastOK.IsZero() {
+		l.log("INFO", "remote domain recovered", map[string]any{"id": id, "url": rd.url})
+	}
+	rd.failing, rd.lastOK = time.Time{}, now
+}
+
+func fetchRemote(ctx context.Context, client *http.Client, u string) (contracts.ControlMessage, error) {
+	var msg contracts.ControlMessage
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return msg, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return msg, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return msg, fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&msg); err != nil {
+		return msg, fmt.Errorf("decode hello: %w", err)
+	}
+	return msg, nil
+}
+
+// stopRemote прекращает опрос и снимает удалённый домен с регистрации (сам удалённый процесс не трогаем).
+func (l *DomainKernelLauncher) stopRemote(id string) bool {
+	l.mu.Lock()
+	rd, ok := l.remotes[id]
+	delete(l.remotes, id)
+	l.mu.Unlock()
+	if !ok {
+		return false
+	}
+	rd.cancel()
+	<-rd.done
+	l.reg.Unregister(id)
+	return true
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"example.com/ffp/platform/contracts"
+)
+
+// Удалённый домен виден в discovery доменным ядром и до первого удачного опроса.
+func TestRemoteDomainRecordBeforeFirstHello(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	reg := NewDiscoveryRegistry()
+	l := NewDomainKernelLauncher(reg, nil, nil, nil)
+	spec := DomainSpec{ID: "far", Mode: "remote", Entry: srv.URL, PollInterval: time.Hour}
+	if err := l.Launch(context.Background(), spec); err != nil {
+		t.Fatal(err)
+	}
+	defer l.Stop("far")
+
+	record := func() KernelRecord {
+		t.Helper()
+		for _, rec := range reg.Kernels() {
+			if rec.ID == "far" {
+				return rec
+			}
+		}
+		t.Fatal("no discovery record for the remote domain")
+		return KernelRecord{}
+	}
+	check := func(when string, rec KernelRecord) {
+		t.Helper()
+		if rec.Scope != contracts.DomainScope || rec.Manifest.Scope != contracts.DomainScope {
+			t.Fatalf("%s: scope = %q, manifest scope = %q; want domain", when, rec.Scope, rec.Manifest.Scope)
+		}
+		if rec.Health.Status != contracts.HealthDegraded {
+			t.Fatalf("%s: health = %+v, want degraded", when, rec.Health)
+		}
+	}
+	check("after launch", record())
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.HasPrefix(record().Health.Reason, "remote unreachable") {
+		if time.Now().After(deadline) {
+			t.Fatalf("health = %+v, want a failed poll", record().Health)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	check("after failed poll", record())
+}
@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"errors"
 	"reflect"
+	"sync"
 	"time"
 
 	"example.com/ffp/platform/contracts"
@@ -15,39 +17,89 @@ type domainRun struct {
 	cancel context.CancelFunc
 	fsm    *rt.FSM
 	kernel rt.KernelModule
+	host   rt.KernelHost
+	err    error // запуск не удался: домен не работает, спецификация (и FSM inproc-домена) сохранена для повтора
 }
 
 type DomainManager struct {
-	reg    *DiscoveryRegistry
-	bus    ports.EventBus
-	logger ports.Logger
-	rpc    ports.RPC
+	reg      *DiscoveryRegistry
+	bus      ports.EventBus
+	logger   ports.Logger
+	rpc      ports.RPC
+	launcher *DomainKernelLauncher
+	hooks    HookTimeouts   // лимиты hook-ов по умолчанию (RootConfig.Hooks)
+	guard    *rt.TopicGuard // nil — домены получают шину без проверки контракта тем
 
+	mu   sync.Mutex // Reload и Restart приходят из разных горутин
 	runs map[string]*domainRun
 }
 
+var errUnknownDomain = errors.New("unknown domain")
+
+// SetHookDefaults задаёт лимиты hook-ов, которые действуют, если домен не задал свои.
+func (m *DomainManager) SetHookDefaults(h HookTimeouts) {
+	m.mu.Lock()
+	m.hooks = h
+	m.mu.Unlock()
+}
+
+// SetTopicGuard задаёт охрану контрактов тем: inproc-домены получают её BusView.
+func (m *DomainManager) SetTopicGuard(g *rt.TopicGuard) {
+	m.mu.Lock()
+	m.guard = g
+	m.mu.Unlock()
+}
+
 func NewDomainManager(reg *DiscoveryRegistry, bus ports.EventBus, logger ports.Logger, rpc ports.RPC) *DomainManager {
-	return &DomainManager{reg: reg, bus: bus, logger: logger, rpc: rpc, runs: make(map[string]*domainRun)}
+	return &DomainManager{
+		reg: reg, bus: bus, logger: logger, rpc: rpc,
+		launcher: NewDomainKernelLauncher(reg, bus, logger, rpc),
+		runs:     make(map[string]*domainRun),
+	}
+}
+
+// launch запускает домен согласно его режиму (inproc | process | remote).
+func (m *DomainManager) launch(ctx context.Context, spec DomainSpec) error {
+	if spec.Mode != "process" && spec.Mode != "remote" {
+		return m.launchInproc(ctx, spec)
+	}
+	if err := m.launcher.Launch(ctx, spec); err != nil {
+		return err
+	}
+	m.runs[spec.ID] = &domainRun{spec: spec}
+	return nil
 }
 
 func (m *DomainManager) launchInproc(ctx context.Context, spec DomainSpec) error {
 	f, ok := domainFactories[spec.Kind]
 	if !ok {
-		// нет фабрики — пусть старый лаунчер решает
-		return NewDomainKernelLauncher(m.reg, m.bus, m.logger, m.rpc).Launch(ctx, spec)
+		// нет фабрики — пусть лаунчер решает
+		return m.launcher.Launch(ctx, spec)
+	}
+	k := f(spec.ID)
+	var bus ports.EventBus = m.bus
+	if m.guard != nil {
+		var strict rt.TopicStrictness // "" — bus.topic_contracts
+		if spec.TopicContracts != "" {
+			strict, _ = rt.ParseTopicStrictness(spec.TopicContracts) // проверено в Validate
+		}
+		bus = m.guard.View(spec.ID, rt.DeclaredTopics(k), strict)
 	}
 	host := rt.NewHost(spec.ID, contracts.DomainScope,
 		ports.WithLogger(ports.NewTeeLogger(m.bus, spec.ID, string(contracts.DomainScope), spec.Kind)),
-		ports.WithEventBus(m.bus),
+		ports.WithEventBus(bus),
 		ports.WithRPC(m.rpc),
 		ports.WithConfig(spec.Config),
+		rt.WithFunctionReporter(m.reg),
 	)
-	k := f(spec.ID)
-	fsm := rt.NewFSM(k, host)
+	fsm := rt.NewFSM(k, host, rt.WithHookTimeouts(spec.Hooks.Merge(m.hooks).Timeouts()))
 
 	dctx, cancel := context.WithCancel(ctx)
 	if err := fsm.Run(dctx, spec.Config); err != nil {
 		cancel()
+		// упавший FSM остаётся в менеджере: история и паника hook-а видны в /lifecycle
+		m.runs[spec.ID] = &domainRun{spec: spec, fsm: fsm, kernel: k, err: err}
+		m.reg.UpdateHealth(spec.ID, contracts.Health{Status: contracts.HealthFailed, Reason: err.Error(), Since: time.Now()})
 		return err
 	}
 
@@ -59,27 +111,97 @@ func (m *DomainManager) launchInproc(ctx context.Context, spec DomainSpec) error
 	ex := &contracts.Exports{
 		Network: []contracts.NetworkEndpoint{{Name: "hello", Protocol: "http", Address: httpAddr, Version: "v1", Endpoints: []string{"/hello"}}},
 	}
+	var im *contracts.Imports
+	if d, ok := k.(rt.ContractDeclarer); ok {
+		// события из контракта ядра — в discovery рядом с HTTP
+		if kex := d.Exports(); kex != nil {
+			ex.Events = kex.Events
+		}
+		im = d.Imports()
+	}
 	m.reg.Register(KernelRecord{
-		ID: spec.ID, Scope: contracts.DomainScope, Manifest: k.Manifest(), Exports: ex,
-		Health: k.Health(), RegisteredAt: time.Now(),
+		ID: spec.ID, Scope: contracts.DomainScope, Manifest: k.Manifest(), Exports: ex, Imports: im,
+		Health: fsm.Health(), RegisteredAt: time.Now(),
 	})
 
-	m.runs[spec.ID] = &domainRun{spec: spec, cancel: cancel, fsm: fsm, kernel: k}
+	m.runs[spec.ID] = &domainRun{spec: spec, cancel: cancel, fsm: fsm, kernel: k, host: host}
+	go m.watchHealth(dctx, spec.ID, fsm)
 	return nil
 }
 
+// healthPollInterval — как часто опрашивается Health() inproc-доменов.
+const healthPollInterval = 5 * time.Second
+
+// watchHealth переносит Health() inproc-домена в FSM (Degrade/Recover/Fail) и в discovery.
+func (m *DomainManager) watchHealth(ctx context.Context, id string, fsm *rt.FSM) {
+	t := time.NewTicker(healthPollInterval)
+	defer t.Stop()
+	var last contracts.Health
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		h := fsm.Health()
+		state := fsm.State()
+		switch {
+		case h.Status == contracts.HealthFailed && state != contracts.StateFailed:
+			_ = fsm.Fail(h.Reason)
+		case h.Status == contracts.HealthDegraded && state == contracts.StateReady:
+			_ = fsm.Degrade(h.Reason)
+		case h.Status == contracts.HealthReady && state == contracts.StateDegraded:
+			_ = fsm.Recover(h.Reason)
+		}
+		if h.Status != last.Status || h.Reason != last.Reason {
+			m.reg.UpdateHealth(id, h)
+			last = h
+		}
+	}
+}
+
 func (m *DomainManager) stop(id string) {
-	if r := m.runs[id]; r != nil {
-		_ = r.fsm.Drain(context.Background())
-		_ = r.fsm.Stop(context.Background())
-		r.cancel()
-		delete(m.runs, id)
+	r := m.runs[id]
+	if r == nil {
+		return
+	}
+	delete(m.runs, id)
+	if r.err != nil {
+		// после неудачного перезапуска останавливать нечего
+		m.reg.UnregisterChildren(id)
 		m.reg.Unregister(id)
+		return
+	}
+	if r.fsm == nil {
+		// домен вне процесса Root-ядра (process/remote) — останавливает лаунчер
+		m.launcher.Stop(id)
+		return
+	}
+	// Drain/Stop ограничены лимитами hook-ов; ошибка Drain не мешает Stop (Failed → Stopped)
+	if err := r.fsm.Drain(context.Background()); err != nil && m.logger != nil {
+		m.logger.Log(context.Background(), "WARN", "domain drain failed", map[string]any{"id": id, "err": err.Error()})
+	}
+	if err := r.fsm.Stop(context.Background()); err != nil && m.logger != nil {
+		m.logger.Log(context.Background(), "WARN", "domain stop failed", map[string]any{"id": id, "err": err.Error()})
+	}
+	r.cancel()
+	m.reg.UnregisterChildren(id)
+	m.reg.Unregister(id)
+}
+
+// StopAll останавливает все домены (при завершении Root-ядра).
+func (m *DomainManager) StopAll() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id := range m.runs {
+		m.stop(id)
 	}
 }
 
 // Reload применяет новый список доменов: стартует/перезапускает/останавливает.
 func (m *DomainManager) Reload(ctx context.Context, specs []DomainSpec) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	index := map[string]DomainSpec{}
 	for _, s := range specs {
 		index[s.ID] = s
@@ -94,7 +216,7 @@ func (m *DomainManager) Reload(ctx context.Context, specs []DomainSpec) {
 	// (re)start changed/new
 	for id, s := range index {
 		if r := m.runs[id]; r == nil {
-			if err := m.launchInproc(ctx, s); err != nil && m.logger != nil {
+			if err := m.launch(ctx, s); err != nil && m.logger != nil {
 				m.logger.Log(ctx, "ERROR", "domain reload launch failed", map[string]any{"id": s.ID, "kind": s.Kind, "err": err.Error()})
 			}
 			continue
@@ -103,9 +225,15 @@ func (m *DomainManager) Reload(ctx context.Context, specs []DomainSpec) {
 			old := r.spec
 			oldFF := old.FeatureFlags
 			newFF := s.FeatureFlags
-			if old.Mode != s.Mode || old.Kind != s.Kind || !reflect.DeepEqual(old.Config, s.Config) || !reflect.DeepEqual(oldFF, newFF) {
-				m.stop(id)
-				if err := m.launchInproc(ctx, s); err != nil && m.logger != nil {
+			procChanged := old.Command != s.Command || !reflect.DeepEqual(old.Args, s.Args) || !reflect.DeepEqual(old.Env, s.Env) ||
+				old.HealthURL != s.HealthURL || old.ReadyTimeout != s.ReadyTimeout || old.Handshake != s.Handshake ||
+				old.StopTimeout != s.StopTimeout || !reflect.DeepEqual(old.Resources, s.Resources) ||
+				old.Entry != s.Entry || old.PollInterval != s.PollInterval || old.FailAfter != s.FailAfter ||
+				old.Restart != s.Restart || old.restartIntensity() != s.restartIntensity() || old.Backoff != s.Backoff || old.ProbeBackoff != s.ProbeBackoff
+			// лимиты hook-ов и строгость тем применяются только при запуске
+			hostChanged := !reflect.DeepEqual(old.Hooks, s.Hooks) || old.TopicContracts != s.TopicContracts
+			if old.Mode != s.Mode || old.Kind != s.Kind || !reflect.DeepEqual(old.Config, s.Config) || !reflect.DeepEqual(oldFF, newFF) || procChanged || hostChanged {
+				if err := m.relaunch(ctx, s); err != nil && m.logger != nil {
 					m.logger.Log(ctx, "ERROR", "domain reload relaunch failed", map[string]any{"id": s.ID, "kind": s.Kind, "err": err.Error()})
 				}
 			} else {
@@ -114,3 +242,54 @@ func (m *DomainManager) Reload(ctx context.Context, specs []DomainSpec) {
 		}
 	}
 }
+
+// Restart перезапускает домен с его текущей спецификацией (в том числе после crash-loop).
+func (m *DomainManager) Restart(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r := m.runs[id]
+	if r == nil {
+		return errUnknownDomain
+	}
+	return m.relaunch(ctx, r.spec)
+}
+
+// relaunch останавливает домен и запускает его по spec. Если запуск не удался, домен
+// остаётся в менеджере и в discovery со статусом Failed — его можно перезапустить снова.
+func (m *DomainManager) relaunch(ctx context.Context, spec DomainSpec) error {
+	m.stop(spec.ID)
+	err := m.launch(ctx, spec)
+	if err == nil {
+		return nil
+	}
+	if r := m.runs[spec.ID]; r != nil {
+		r.err = err // launch уже сохранил упавший FSM
+	} else {
+		m.runs[spec.ID] = &domainRun{spec: spec, err: err}
+	}
+	m.reg.UpdateHealth(spec.ID, contracts.Health{Status: contracts.HealthFailed, Reason: "restart failed: " + err.Error(), Since: time.Now()})
+	return err
+}
+
+// FSM возвращает автомат жизненного цикла домена (состояние, история, паники).
+// false — домена нет или он живёт вне процесса Root-ядра (без FSM).
+func (m *DomainManager) FSM(id string) (*rt.FSM, bool) {
+	m.mu.Lock()
+	r := m.runs[id]
+	m.mu.Unlock()
+	if r == nil || r.fsm == nil {
+		return nil, false
+	}
+	return r.fsm, true
+}
+
+// Supervisors возвращает супервизоры, открытые inproc-доменом через host.ExposeSupervisor.
+func (m *DomainManager) Supervisors(id string) (map[string]*rt.Supervisor, bool) {
+	m.mu.Lock()
+	r := m.runs[id]
+	m.mu.Unlock()
+	if r == nil || r.host == nil {
+		return nil, false
+	}
+	return r.host.Supervisors(), true
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"example.com/ffp/platform/contracts"
+	rt "example.com/ffp/platform/runtime"
+)
+
+// panicOnStart — домен, падающий паникой в OnStart.
+type panicOnStart struct{ rt.KernelModuleBase }
+
+func (panicOnStart) Manifest() contracts.Manifest {
+	return contracts.Manifest{KernelID: "panicky", Version: "1.0.0", Scope: contracts.DomainScope}
+}
+
+func (panicOnStart) OnStart(context.Context) error { panic("boom") }
+
+// История и паника домена, упавшего при запуске, доступны в /admin/kernels/{id}/lifecycle
+// и после неудачного Restart.
+func TestLifecycleOfFailedDomain(t *testing.T) {
+	RegisterDomainFactory("test-panic-on-start", func(string) rt.KernelModule { return panicOnStart{} })
+	defer delete(domainFactories, "test-panic-on-start")
+
+	ctx := context.Background()
+	reg := NewDiscoveryRegistry()
+	mgr := NewDomainManager(reg, rt.NewInMemoryEventBus(), nil, nil)
+	defer mgr.StopAll()
+	admin := NewAdminServer("", reg, nil)
+	admin.SetDomainManager(mgr)
+	admin.AddKernelControlHandlers()
+
+	lifecycle := func() (state contracts.LifecycleState, history []rt.Transition, panicked *rt.PanicError) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		admin.srv.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/kernels/d1/lifecycle", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET lifecycle = %d %s", w.Code, w.Body)
+		}
+		var body struct {
+			State     contracts.LifecycleState `json:"state"`
+			History   []rt.Transition          `json:"history"`
+			LastPanic *rt.PanicError           `json:"last_panic"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		return body.State, body.History, body.LastPanic
+	}
+	check := func(when string) {
+		t.Helper()
+		state, history, p := lifecycle()
+		if state != contracts.StateFailed {
+			t.Fatalf("%s: state = %s, want failed", when, state)
+		}
+		if p == nil || p.Hook != "OnStart" || p.Value != "boom" {
+			t.Fatalf("%s: last_panic = %+v, want OnStart boom", when, p)
+		}
+		if n := len(history); n == 0 || history[n-1].To != contracts.StateFailed || history[n-1].Stack == "" {
+			t.Fatalf("%s: history = %+v, want last transition to failed with stack", when, history)
+		}
+	}
+
+	spec := DomainSpec{ID: "d1", Kind: "test-panic-on-start", Mode: "inproc"}
+	if err := mgr.launch(ctx, spec); err == nil {
+		t.Fatal("launch succeeded, want OnStart panic")
+	}
+	check("after launch")
+
+	if err := mgr.Restart(ctx, "d1"); err == nil {
+		t.Fatal("Restart succeeded, want OnStart panic")
+	}
+	check("after restart")
+	if h := reg.KernelHealth()["d1"]; h.Status != contracts.HealthFailed {
+		t.Fatalf("discovery health = %+v, want failed", h)
+	}
+}
+
+// okKernel — домен, который просто запускается.
+type okKernel struct{ rt.KernelModuleBase }
+
+func (okKernel) Manifest() contracts.Manifest {
+	return contracts.Manifest{KernelID: "ok", Version: "1.0.0", Scope: contracts.DomainScope}
+}
+
+// Лимиты hook-ов Root сливаются только при запуске: Reload с той же спецификацией домен не трогает.
+func TestReloadKeepsDomainWithRootHookDefaults(t *testing.T) {
+	RegisterDomainFactory("test-ok", func(string) rt.KernelModule { return okKernel{} })
+	defer delete(domainFactories, "test-ok")
+
+	ctx := context.Background()
+	mgr := NewDomainManager(NewDiscoveryRegistry(), rt.NewInMemoryEventBus(), nil, nil)
+	defer mgr.StopAll()
+	mgr.SetHookDefaults(HookTimeouts{Start: time.Second, Stop: time.Second})
+
+	spec := DomainSpec{ID: "d1", Kind: "test-ok", Mode: "inproc", Hooks: HookTimeouts{Start: 2 * time.Second}}
+	if err := mgr.launch(ctx, spec); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := mgr.FSM("d1")
+	mgr.Reload(ctx, []DomainSpec{spec})
+	if after, _ := mgr.FSM("d1"); after != before {
+		t.Fatal("Reload with an unchanged spec relaunched the domain")
+	}
+
+	spec.Hooks.Start = 3 * time.Second
+	mgr.Reload(ctx, []DomainSpec{spec})
+	if after, _ := mgr.FSM("d1"); after == before {
+		t.Fatal("Reload with changed hook timeouts kept the old domain")
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"example.com/ffp/platform/contracts"
 	"example.com/ffp/platform/ports"
+	rt "example.com/ffp/platform/runtime"
 )
 
 type HealthAggregator struct {
@@ -14,11 +15,36 @@ type HealthAggregator struct {
 	last   atomic.Value // contracts.Health
 	bus    ports.EventBus
 	logger ports.Logger
+	clock  rt.Clock
+	env    *ports.EnvelopeBus // публикация сводки конвертом contracts.Health
 }
 
-func NewHealthAggregator(reg *DiscoveryRegistry, bus ports.EventBus, logger ports.Logger) *HealthAggregator {
-	h := &HealthAggregator{reg: reg, bus: bus, logger: logger}
-	h.last.Store(contracts.Health{Status: contracts.HealthReady, Since: time.Now()})
+type HealthAggregatorOption func(*HealthAggregator)
+
+// WithHealthClock задаёт часы периода опроса (по умолчанию rt.SystemClock).
+func WithHealthClock(c rt.Clock) HealthAggregatorOption {
+	return func(h *HealthAggregator) {
+		if c != nil {
+			h.clock = c
+		}
+	}
+}
+
+// WithHealthEnvelopes задаёт обёртку шины для публикации (например, с заголовком zone узла).
+func WithHealthEnvelopes(eb *ports.EnvelopeBus) HealthAggregatorOption {
+	return func(h *HealthAggregator) {
+		if eb != nil {
+			h.env = eb
+		}
+	}
+}
+
+func NewHealthAggregator(reg *DiscoveryRegistry, bus ports.EventBus, logger ports.Logger, opts ...HealthAggregatorOption) *HealthAggregator {
+	h := &HealthAggregator{reg: reg, bus: bus, logger: logger, clock: rt.SystemClock, env: ports.NewEnvelopeBus(bus)}
+	for _, o := range opts {
+		o(h)
+	}
+	h.last.Store(contracts.Health{Status: contracts.HealthReady, Since: h.clock.Now()})
 	return h
 }
 
@@ -26,24 +52,29 @@ func (h *HealthAggregator) Snapshot() contracts.Health {
 	if v := h.last.Load(); v != nil {
 		return v.(contracts.Health)
 	}
-	return contracts.Health{Status: contracts.HealthReady, Since: time.Now()}
+	return contracts.Health{Status: contracts.HealthReady, Since: h.clock.Now()}
 }
 
 func (h *HealthAggregator) Run(ctx context.Context, interval time.Duration) {
 	if interval <= 0 {
 		interval = 2 * time.Second
 	}
-	t := time.NewTicker(interval)
+	t := h.clock.NewTicker(interval)
 	defer t.Stop()
+	h.publish(ctx) // сразу: тема retained, поздние подписчики не ждут тика
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-t.C:
-			v := h.reg.AggregateHealth()
-			h.last.Store(v)
-			// опционально можно публиковать в шину (пригодится позже)
-			_ = h.bus.Publish(ctx, "telemetry.health.root", v)
+		case <-t.C():
+			h.publish(ctx)
 		}
 	}
 }
+
+func (h *HealthAggregator) publish(ctx context.Context) {
+	v := h.reg.AggregateHealth()
+	h.last.Store(v)
+	// конверт типа contracts.health.v1 с ключом rk
+	_ = h.env.Publish(ctx, rt.TopicTelemetryHealthRoot, v, ports.WithKey("rk"))
+}
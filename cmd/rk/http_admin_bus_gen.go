@@ -0,0 +1,242 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"example.com/ffp/platform/ports"
+	rt "example.com/ffp/platform/runtime"
+)
+
+// AddBusStats регистрирует GET /admin/bus/stats — доставлено/потеряно по подпискам шины.
+func (s *AdminServer) AddBusStats(bus ports.EventBus) {
+	mux, _ := s.srv.Handler.(*http.ServeMux)
+	mux.HandleFunc("/admin/bus/stats", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		sp, ok := bus.(ports.StatsProvider)
+		if !ok {
+			http.Error(w, "event bus does not expose stats", http.StatusNotImplemented)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(sp.Stats())
+	})
+}
+
+// AddBusContracts регистрирует GET /admin/bus/contracts — контракты тем ядер
+// (exports/imports, строгость) и число обращений к необъявленным темам по каждому ядру.
+func (s *AdminServer) AddBusContracts(g *rt.TopicGuard) {
+	mux, _ := s.srv.Handler.(*http.ServeMux)
+	mux.HandleFunc("/admin/bus/contracts", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"kernels": g.Report()})
+	})
+}
+
+// AddBusRetained регистрирует /admin/bus/retained: GET — темы с сохранённым значением,
+// DELETE ?topic=<шаблон> — забыть значения.
+func (s *AdminServer) AddBusRetained(bus ports.EventBus) {
+	mux, _ := s.srv.Handler.(*http.ServeMux)
+	mux.HandleFunc("/admin/bus/retained", func(w http.ResponseWriter, r *http.Request) {
+		rb, ok := bus.(ports.Retainer)
+		if !ok {
+			http.Error(w, "event bus does not support retained topics", http.StatusNotImplemented)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]any{"topics": rb.RetainedTopics()})
+		case http.MethodDelete:
+			topic := r.URL.Query().Get("topic")
+			if err := ports.ValidateTopicPattern(topic); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"cleared": rb.ClearRetained(topic)})
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// busTopic — строка GET /admin/bus/topics: статистика шины и ядра, объявившие тему в реестре.
+type busTopic struct {
+	ports.TopicStats
+	Exporters []string `json:"exporters"`
+	Importers []string `json:"importers"`
+}
+
+// AddBusTopics регистрирует GET /admin/bus/topics — темы шины с числом подписок, скоростью
+// публикаций и потерями, а также ядра, экспортирующие/импортирующие тему по реестру.
+// Точные (без шаблонов) объявленные темы попадают в список, даже если в них ещё не публиковали.
+func (s *AdminServer) AddBusTopics(bus ports.EventBus) {
+	mux, _ := s.srv.Handler.(*http.ServeMux)
+	mux.HandleFunc("/admin/bus/topics", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		tp, ok := bus.(ports.TopicStatsProvider)
+		if !ok {
+			http.Error(w, "event bus does not expose topic stats", http.StatusNotImplemented)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"topics": busTopics(tp.TopicStats(), bus, s.reg.Kernels())})
+	})
+}
+
+func busTopics(stats []ports.TopicStats, bus ports.EventBus, kernels []KernelRecord) []busTopic {
+	type declared struct{ kernel, pattern string }
+	var exports, imports []declared
+	for _, k := range kernels {
+		ex, im := rt.BridgeTopics(k.Exports, k.Imports)
+		for _, p := range ex {
+			exports = append(exports, declared{k.ID, p})
+		}
+		for _, p := range im {
+			imports = append(imports, declared{k.ID, p})
+		}
+	}
+
+	rows := make(map[string]*busTopic, len(stats))
+	for _, st := range stats {
+		rows[st.Topic] = &busTopic{TopicStats: st}
+	}
+	for _, d := range append(exports, imports...) {
+		if _, ok := rows[d.pattern]; ok || ports.IsWildcard(d.pattern) {
+			continue
+		}
+		row := &busTopic{TopicStats: ports.TopicStats{Topic: d.pattern}}
+		if sc, ok := bus.(ports.SubscriberCounter); ok {
+			row.Subscribers = sc.Subscribers(d.pattern)
+		}
+		rows[d.pattern] = row
+	}
+
+	who := func(decl []declared, topic string) []string {
+		out := []string{}
+		for _, d := range decl {
+			if ports.MatchTopic(d.pattern, topic) && (len(out) == 0 || out[len(out)-1] != d.kernel) {
+				out = append(out, d.kernel)
+			}
+		}
+		sort.Strings(out)
+		return out
+	}
+	out := make([]busTopic, 0, len(rows))
+	for _, row := range rows {
+		row.Exporters, row.Importers = who(exports, row.Topic), who(imports, row.Topic)
+		out = append(out, *row)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Topic < out[j].Topic })
+	return out
+}
+
+// tapEvent — событие SSE /admin/bus/tap: конверт с темой; Payload — JSON как есть, иначе строка.
+type tapEvent struct {
+	Topic      string            `json:"topic"`
+	ID         string            `json:"id"`
+	Type       string            `json:"type"`
+	Key        string            `json:"key,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	OccurredAt time.Time         `json:"occurred_at"`
+	Payload    any               `json:"payload,omitempty"`
+}
+
+// AddBusTap регистрирует GET /admin/bus/tap?topic=<шаблон>[&topic=...] — SSE-поток
+// сообщений произвольных тем (по умолчанию ">"). Подписка — как у /admin/logs/stream:
+// при отставании клиента теряются самые старые сообщения.
+func (s *AdminServer) AddBusTap(bus ports.EventBus) {
+	mux, _ := s.srv.Handler.(*http.ServeMux)
+	mux.HandleFunc("/admin/bus/tap", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+		patterns := r.URL.Query()["topic"]
+		if len(patterns) == 0 {
+			patterns = []string{">"}
+		}
+		for _, p := range patterns {
+			if err := ports.ValidateTopicPattern(p); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		ctx := r.Context()
+		ch, cancel, err := ports.SubscribeAll(ctx, bus, patterns,
+			ports.WithSubscriber("admin/bus/tap"),
+			ports.WithBufferSize(256),
+			ports.WithOverflow(ports.DropOldest),
+			ports.WithMessages(),
+		)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer cancel()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+
+		keep := time.NewTicker(10 * time.Second)
+		defer keep.Stop()
+
+		write := func(tag string, v any) {
+			b, _ := json.Marshal(v)
+			w.Write([]byte("event: " + tag + "\ndata: "))
+			w.Write(b)
+			w.Write([]byte("\n\n"))
+			flusher.Flush()
+		}
+		write("hello", map[string]any{"status": "ok", "topics": patterns})
+
+		eb := ports.NewEnvelopeBus(bus)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-keep.C:
+				w.Write([]byte(": keep-alive\n\n")) // комментарий SSE
+				flusher.Flush()
+			case v, ok := <-ch:
+				if !ok {
+					return
+				}
+				m, ok := v.(ports.Message)
+				if !ok {
+					continue // шина не поддерживает WithMessages: тема неизвестна
+				}
+				env, ok := eb.Wrap(m.Value)
+				if !ok {
+					continue
+				}
+				ev := tapEvent{Topic: m.Topic, ID: env.ID, Type: env.Type, Key: env.Key, Headers: env.Headers, OccurredAt: env.OccurredAt}
+				if json.Valid(env.Payload) {
+					ev.Payload = json.RawMessage(env.Payload)
+				} else if len(env.Payload) > 0 {
+					ev.Payload = string(env.Payload)
+				}
+				write("message", ev)
+			}
+		}
+	})
+}
@@ -2,17 +2,22 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
 	"example.com/ffp/platform/contracts"
+	rt "example.com/ffp/platform/runtime"
 )
 
 func (s *AdminServer) AddKernelControlHandlers() {
 	mux, _ := s.srv.Handler.(*http.ServeMux)
 	mux.HandleFunc("/admin/kernels/", func(w http.ResponseWriter, r *http.Request) {
-		// /admin/kernels/{id}/restart | /drain
+		// POST /admin/kernels/{id}/restart | /drain | /workers/{name}/restart|suspend|resume,
+		// GET /admin/kernels/{id}/lifecycle | /workers
 		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/admin/kernels/"), "/")
 		if len(parts) < 2 {
 			http.Error(w, "bad path", http.StatusBadRequest)
@@ -22,11 +27,23 @@ func (s *AdminServer) AddKernelControlHandlers() {
 		switch r.Method {
 		case http.MethodPost:
 			switch action {
+			case "workers":
+				s.handleWorkerAction(w, r, id, parts[2:])
 			case "restart":
-				// Демоверсия: помечаем health как ready
-				s.reg.UpdateHealth(id, contracts.Health{Status: contracts.HealthReady, Since: time.Now(), Reason: "manual restart (placeholder)"})
-				w.WriteHeader(http.StatusAccepted)
-				_ = json.NewEncoder(w).Encode(map[string]any{"status": "accepted", "action": "restart", "id": id})
+				// перезапуск синхронный: ответ — его результат (Stop и запуск ограничены лимитами домена)
+				if s.domains == nil {
+					http.Error(w, errUnknownDomain.Error(), http.StatusNotFound)
+					return
+				}
+				switch err := s.domains.Restart(s.ctx, id); {
+				case errors.Is(err, errUnknownDomain):
+					http.Error(w, err.Error(), http.StatusNotFound)
+				case err != nil:
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+				default:
+					w.Header().Set("Content-Type", "application/json")
+					_ = json.NewEncoder(w).Encode(map[string]any{"status": "ok", "action": "restart", "id": id})
+				}
 			case "drain":
 				s.reg.UpdateHealth(id, contracts.Health{Status: contracts.HealthDraining, Since: time.Now(), Reason: "manual drain (placeholder)"})
 				w.WriteHeader(http.StatusAccepted)
@@ -34,8 +51,106 @@ func (s *AdminServer) AddKernelControlHandlers() {
 			default:
 				http.Error(w, "unknown action", http.StatusNotFound)
 			}
+		case http.MethodGet:
+			switch action {
+			case "lifecycle":
+				if s.domains == nil {
+					http.Error(w, "no lifecycle for kernel", http.StatusNotFound)
+					return
+				}
+				fsm, ok := s.domains.FSM(id)
+				if !ok {
+					http.Error(w, "no lifecycle for kernel", http.StatusNotFound)
+					return
+				}
+				state := fsm.State()
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"id":          id,
+					"state":       state,
+					"transitions": state.Transitions(),
+					"history":     fsm.History(),
+					"last_panic":  fsm.LastPanic(),
+				})
+			case "workers":
+				s.handleKernelWorkers(w, id)
+			default:
+				http.Error(w, "unknown action", http.StatusNotFound)
+			}
 		default:
 			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		}
 	})
 }
+
+// handleKernelWorkers отдаёт снимки супервизоров, открытых ядром (host.ExposeSupervisor).
+func (s *AdminServer) handleKernelWorkers(w http.ResponseWriter, id string) {
+	if s.domains == nil {
+		http.Error(w, "no supervisors for kernel", http.StatusNotFound)
+		return
+	}
+	sups, ok := s.domains.Supervisors(id)
+	if !ok {
+		http.Error(w, "no supervisors for kernel", http.StatusNotFound)
+		return
+	}
+	snaps := make(map[string]rt.SupervisorSnapshot, len(sups))
+	for name, sup := range sups {
+		snaps[name] = sup.Snapshot()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"id": id, "supervisors": snaps})
+}
+
+// handleWorkerAction выполняет ручную команду над воркером: rest = [name..., action],
+// имя может быть путём через вложенные супервизоры ("site-workers/fk-hello-http").
+// ?supervisor= выбирает открытый ядром супервизор, иначе воркер ищется во всех.
+func (s *AdminServer) handleWorkerAction(w http.ResponseWriter, r *http.Request, id string, rest []string) {
+	if len(rest) < 2 {
+		http.Error(w, "bad path", http.StatusBadRequest)
+		return
+	}
+	name, action := strings.Join(rest[:len(rest)-1], "/"), rest[len(rest)-1]
+	var do func(*rt.Supervisor, string) error
+	switch action {
+	case "restart":
+		do = (*rt.Supervisor).Restart
+	case "suspend":
+		do = (*rt.Supervisor).Suspend
+	case "resume":
+		do = (*rt.Supervisor).Resume
+	default:
+		http.Error(w, "unknown action", http.StatusNotFound)
+		return
+	}
+	var sups map[string]*rt.Supervisor
+	if s.domains != nil {
+		sups, _ = s.domains.Supervisors(id)
+	}
+	names := make([]string, 0, len(sups))
+	for n := range sups {
+		if q := r.URL.Query().Get("supervisor"); q == "" || q == n {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+	err := fmt.Errorf("%w %q", rt.ErrUnknownWorker, name)
+	for _, n := range names {
+		sup, wname, lerr := sups[n].Lookup(name)
+		if lerr != nil {
+			continue
+		}
+		if err = do(sup, wname); !errors.Is(err, rt.ErrUnknownWorker) {
+			break
+		}
+	}
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "accepted", "action": action, "id": id, "worker": name})
+	case errors.Is(err, rt.ErrUnknownWorker):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusConflict)
+	}
+}
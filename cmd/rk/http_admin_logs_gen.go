@@ -41,21 +41,24 @@ func (s *AdminServer) AddLogStream(bus ports.EventBus) {
 		filter.Scope = r.URL.Query().Get("scope")
 		filter.Component = r.URL.Query().Get("component")
 
+		// одна подписка — каждая запись приходит ровно один раз:
+		// все логи публикуются в telemetry.logs, логи scope — ещё и в telemetry.logs.<scope>
 		ctx := r.Context()
-		chAll, cancelAll, _ := bus.Subscribe(ctx, "telemetry.logs")
-		defer cancelAll()
-
-		// по желанию можно ещё и на scope-специфичную тему подписаться
-		var chScope <-chan any
-		var cancelScope func()
+		topic := "telemetry.logs"
 		if filter.Scope != "" {
-			chScope, cancelScope, _ = bus.Subscribe(ctx, "telemetry.logs."+filter.Scope)
-			defer func() {
-				if cancelScope != nil {
-					cancelScope()
-				}
-			}()
+			topic = "telemetry.logs." + filter.Scope
+		}
+		// живой поток: при отставании клиента теряем самые старые записи, потери видны в /admin/bus/stats
+		ch, cancel, err := bus.Subscribe(ctx, topic,
+			ports.WithSubscriber("admin/logs/stream"),
+			ports.WithBufferSize(256),
+			ports.WithOverflow(ports.DropOldest),
+		)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
+		defer cancel()
 
 		keep := time.NewTicker(10 * time.Second)
 		defer keep.Stop()
@@ -79,21 +82,9 @@ func (s *AdminServer) AddLogStream(bus ports.EventBus) {
 			case <-keep.C:
 				w.Write([]byte(": keep-alive\n\n")) // комментарий SSE
 				flusher.Flush()
-			case m := <-chAll:
-				if m == nil {
-					continue
-				}
-				switch rec := m.(type) {
-				case telemetry.LogRecordV2:
-					if filter.MatchV2(rec) {
-						_ = write("log", rec)
-					}
-				default:
-					// игнорируем неизвестные типы
-				}
-			case m := <-chScope:
-				if m == nil {
-					continue
+			case m, ok := <-ch:
+				if !ok {
+					return
 				}
 				if rec, ok := m.(telemetry.LogRecordV2); ok && filter.MatchV2(rec) {
 					_ = write("log", rec)
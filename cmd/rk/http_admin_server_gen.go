@@ -10,16 +10,19 @@ import (
 )
 
 type AdminServer struct {
-	srv    *http.Server
-	reg    *DiscoveryRegistry
-	logger ports.Logger
-	health *HealthAggregator
+	srv     *http.Server
+	reg     *DiscoveryRegistry
+	logger  ports.Logger
+	health  *HealthAggregator
+	domains *DomainManager
+
+	ctx context.Context // контекст Root-ядра: действия админки переживают HTTP-запрос
 }
 
 func NewAdminServer(addr string, reg *DiscoveryRegistry, logger ports.Logger) *AdminServer {
 	mux := http.NewServeMux()
 	srv := &http.Server{Addr: addr, Handler: mux}
-	s := &AdminServer{srv: srv, reg: reg, logger: logger}
+	s := &AdminServer{srv: srv, reg: reg, logger: logger, ctx: context.Background()}
 	s.registerBaseHandlers()
 	return s
 }
@@ -49,7 +52,13 @@ func (s *AdminServer) SetHealthAggregator(h *HealthAggregator) {
 	s.health = h
 }
 
+// SetDomainManager подключает менеджер доменов к управляющим ручкам (restart).
+func (s *AdminServer) SetDomainManager(m *DomainManager) {
+	s.domains = m
+}
+
 func (s *AdminServer) Start(ctx context.Context) error {
+	s.ctx = ctx
 	errCh := make(chan error, 1)
 	go func() {
 		err := s.srv.ListenAndServe()
@@ -20,8 +20,8 @@ func (h *LogHub) Publish(ctx context.Context, rec telemetry.LogRecordV2) {
 	}
 	_ = h.bus.Publish(ctx, rt.TopicTelemetryLogsAll, rec)
 	_ = h.bus.Publish(ctx, rt.TopicTelemetryLogsRoot, rec)
-	// при желании можно добавить scope-специфичные темы: telemetry.logs.<scope>
-	if rec.Scope != "" {
+	// scope-специфичная тема: telemetry.logs.<scope> (root уже опубликован выше — без дубля)
+	if rec.Scope != "" && rec.Scope != "root" {
 		_ = h.bus.Publish(ctx, "telemetry.logs."+rec.Scope, rec)
 	}
 }
@@ -9,6 +9,7 @@ import (
 
 	"example.com/ffp/platform/contracts"
 	"example.com/ffp/platform/ports"
+	rt "example.com/ffp/platform/runtime"
 )
 
 var configPath string
@@ -18,8 +19,11 @@ func RunRootKernel(ctx context.Context, cfg RootConfig) error {
 		return err
 	}
 
-	bus := NewInMemoryEventBus()
+	bus := rt.NewInMemoryEventBus()
 	logger := NewStdLogger("rk")
+	for _, p := range append([]string{rt.TopicTelemetryHealthRoot}, cfg.Bus.Retained...) {
+		_ = bus.Retain(p) // шаблоны проверены в Validate
+	}
 
 	reg := NewDiscoveryRegistry()
 	reg.RegisterKernel(contracts.Manifest{KernelID: "rk", Scope: contracts.RootScope, Version: "0.0.1"})
@@ -37,19 +41,61 @@ func RunRootKernel(ctx context.Context, cfg RootConfig) error {
 	admin := NewAdminServer(cfg.Admin.Addr, reg, logger)
 	admin.AddKernelControlHandlers()
 	admin.AddLogStream(bus)
+	admin.AddBusStats(bus)
+	admin.AddBusRetained(bus)
+	admin.AddBusTopics(bus)
+	admin.AddBusTap(bus)
 	admin.AddTelemetryHandlers()
 
 	// запустим сводку здоровья
-	ha := NewHealthAggregator(reg, bus, logger)
+	envBus := ports.NewEnvelopeBus(bus, ports.WithDefaultHeaders(map[string]string{contracts.HeaderZone: cfg.Root.Zone}))
+	ha := NewHealthAggregator(reg, bus, logger, WithHealthEnvelopes(envBus))
 	admin.SetHealthAggregator(ha)
 	go ha.Run(ctx, 2*time.Second)
 
 	var rpc ports.RPC
 
-	launcher := NewDomainKernelLauncher(reg, bus, logger, rpc)
 	mgr := NewDomainManager(reg, bus, logger, rpc)
+	launcher := mgr.launcher
+	admin.SetDomainManager(mgr)
+	mgr.SetHookDefaults(cfg.Hooks)
+	strict, _ := rt.ParseTopicStrictness(cfg.Bus.TopicContracts) // проверено в Validate
+	guard := rt.NewTopicGuard(bus, strict, logger)
+	mgr.SetTopicGuard(guard)
+	admin.AddBusContracts(guard)
+	launcher.SetLogHub(hub)
+	launcher.SetCgroupParent(cfg.Resources.CgroupParent)
+	if cfg.Bus.BridgeSocket != "" {
+		socket, cleanup, err := bridgeSocketPath(cfg.Bus.BridgeSocket)
+		if err != nil {
+			logger.Log(ctx, "ERROR", "bus bridge disabled", map[string]any{"err": err.Error()})
+		} else {
+			launcher.SetBusSocket(socket)
+			bridge := NewBusBridgeServer(bus, reg, launcher, guard, cfg.Root.NodeID, logger)
+			go func() {
+				defer cleanup()
+				if err := bridge.Serve(ctx, socket); err != nil {
+					logger.Log(ctx, "ERROR", "bus bridge stopped", map[string]any{"socket": socket, "err": err.Error()})
+				}
+			}()
+		}
+	}
 
 	for _, d := range cfg.Domains {
+		if d.Mode == "process" {
+			if err := mgr.launch(ctx, d); err != nil {
+				logger.Log(ctx, "ERROR", "process domain launch failed", map[string]any{"id": d.ID, "command": d.Command, "err": err.Error()})
+			} else {
+				logger.Log(ctx, "INFO", "process domain launched", map[string]any{"id": d.ID, "command": d.Command})
+			}
+			continue
+		}
+		if d.Mode == "remote" {
+			if err := mgr.launch(ctx, d); err != nil {
+				logger.Log(ctx, "ERROR", "remote domain attach failed", map[string]any{"id": d.ID, "entry": d.Entry, "err": err.Error()})
+			}
+			continue
+		}
 		if d.Mode == "inproc" {
 			if handled, err := func() (bool, error) {
 				if _, ok := domainFactories[d.Kind]; ok {
@@ -69,8 +115,11 @@ func RunRootKernel(ctx context.Context, cfg RootConfig) error {
 			}
 		}
 
-		// Сначала пробуем через фабрику (site и др.)
-		if handled, err := LaunchDomainWithFactory(ctx, bus, logger, rpc, reg, d); handled {
+		// Сначала пробуем через фабрику (site и др.). Менеджер сам сливает лимиты hook-ов
+		// с RootConfig.Hooks, а фабрике они передаются уже слитыми — в копии spec.
+		fd := d
+		fd.Hooks = d.Hooks.Merge(cfg.Hooks)
+		if handled, err := LaunchDomainWithFactory(ctx, bus, logger, rpc, reg, fd); handled {
 			if err != nil {
 				logger.Log(ctx, "ERROR", "factory launch failed", map[string]any{"id": d.ID, "kind": d.Kind, "err": err.Error()})
 			} else {
@@ -97,6 +146,7 @@ func RunRootKernel(ctx context.Context, cfg RootConfig) error {
 	go func() {
 		for range hup {
 			if cfg2, err := LoadConfig(configPath); err == nil {
+				mgr.SetHookDefaults(cfg2.Hooks)
 				mgr.Reload(ctx, cfg2.Domains)
 				logger.Log(ctx, "INFO", "config reloaded (domains)", map[string]any{"count": len(cfg2.Domains)})
 			} else {
@@ -107,6 +157,8 @@ func RunRootKernel(ctx context.Context, cfg RootConfig) error {
 
 	select {
 	case <-ctx.Done():
+		// OnDrain/OnStop доменов ограничены лимитами hook-ов, процессы — stop_timeout
+		mgr.StopAll()
 		return nil
 	case err := <-errCh:
 		return err
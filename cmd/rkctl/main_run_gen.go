@@ -10,6 +10,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -25,6 +26,10 @@ func usage() {
   rkctl kernels health [--http URL]
   rkctl kernels restart --id ID [--http URL]
   rkctl kernels drain   --id ID [--http URL]
+  rkctl kernels workers --id ID [--http URL] [--json]
+  rkctl kernels workers restart|suspend|resume --id ID --name WORKER [--supervisor S] [--http URL]
+  rkctl bus topics [--http URL] [--json]
+  rkctl bus tap <pattern> [pattern...] [--http URL] [--pretty]
 
 По умолчанию --http=http://localhost:8090
 `)
@@ -52,6 +57,28 @@ func main() {
 			cmdKernelsAction(os.Args[3:], "restart")
 		case "drain":
 			cmdKernelsAction(os.Args[3:], "drain")
+		case "workers":
+			if len(os.Args) > 3 {
+				switch a := os.Args[3]; a {
+				case "restart", "suspend", "resume":
+					cmdWorkerAction(os.Args[4:], a)
+					return
+				}
+			}
+			cmdKernelsWorkers(os.Args[3:])
+		default:
+			usage()
+		}
+	case "bus":
+		if len(os.Args) < 3 {
+			usage()
+			return
+		}
+		switch os.Args[2] {
+		case "topics":
+			cmdBusTopics(os.Args[3:])
+		case "tap":
+			cmdBusTap(os.Args[3:])
 		default:
 			usage()
 		}
@@ -202,6 +229,118 @@ func cmdKernelsAction(args []string, action string) {
 	ioCopy(os.Stdout, resp.Body)
 }
 
+// workerInfo/supervisorSnapshot — ответ GET /admin/kernels/{id}/workers (runtime.SupervisorSnapshot).
+type workerInfo struct {
+	Name        string              `json:"name"`
+	Policy      string              `json:"policy"`
+	State       string              `json:"state"`
+	Attempts    int                 `json:"attempts"`
+	LastError   string              `json:"last_error"`
+	StartedAt   time.Time           `json:"started_at"`
+	NextRestart *time.Time          `json:"next_restart"`
+	Supervisor  *supervisorSnapshot `json:"supervisor"`
+}
+
+type supervisorSnapshot struct {
+	Strategy      string       `json:"strategy"`
+	MaxRestarts   int          `json:"max_restarts"`
+	RestartWindow string       `json:"restart_window"`
+	Restarts      int          `json:"restarts"`
+	Stopped       bool         `json:"stopped"`
+	Err           string       `json:"error"`
+	Workers       []workerInfo `json:"workers"`
+}
+
+func cmdKernelsWorkers(args []string) {
+	fs := flag.NewFlagSet("kernels workers", flag.ExitOnError)
+	httpURL := fs.String("http", defaultHTTP(), "Base URL admin HTTP")
+	id := fs.String("id", "", "Kernel ID")
+	raw := fs.Bool("json", false, "Raw JSON")
+	_ = fs.Parse(args)
+
+	if *id == "" {
+		fmt.Fprintln(os.Stderr, "--id is required")
+		return
+	}
+	url := fmt.Sprintf("%s/admin/kernels/%s/workers", strings.TrimRight(*httpURL, "/"), *id)
+	resp, err := http.Get(url)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "http error:", err)
+		return
+	}
+	defer resp.Body.Close()
+	if *raw || resp.StatusCode != http.StatusOK {
+		ioCopy(os.Stdout, resp.Body)
+		return
+	}
+	var out struct {
+		Supervisors map[string]supervisorSnapshot `json:"supervisors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		fmt.Fprintln(os.Stderr, "decode error:", err)
+		return
+	}
+	names := make([]string, 0, len(out.Supervisors))
+	for n := range out.Supervisors {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	fmt.Printf("%-28s %-10s %-11s %8s  %-8s %-12s %s\n", "WORKER", "POLICY", "STATE", "ATTEMPTS", "UPTIME", "NEXT", "LAST ERROR")
+	for _, n := range names {
+		printSupervisor(n, out.Supervisors[n], 0)
+	}
+}
+
+func cmdWorkerAction(args []string, action string) {
+	fs := flag.NewFlagSet("kernels workers "+action, flag.ExitOnError)
+	httpURL := fs.String("http", defaultHTTP(), "Base URL admin HTTP")
+	id := fs.String("id", "", "Kernel ID")
+	name := fs.String("name", "", "Worker name (nested: parent/child)")
+	sup := fs.String("supervisor", "", "Exposed supervisor name (default: search all)")
+	_ = fs.Parse(args)
+
+	if *id == "" || *name == "" {
+		fmt.Fprintln(os.Stderr, "--id and --name are required")
+		return
+	}
+	url := fmt.Sprintf("%s/admin/kernels/%s/workers/%s/%s", strings.TrimRight(*httpURL, "/"), *id, strings.Trim(*name, "/"), action)
+	if *sup != "" {
+		url += "?supervisor=" + urlQueryEsc(*sup)
+	}
+	req, _ := http.NewRequest(http.MethodPost, url, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "http error:", err)
+		return
+	}
+	defer resp.Body.Close()
+	ioCopy(os.Stdout, resp.Body)
+}
+
+func printSupervisor(name string, s supervisorSnapshot, depth int) {
+	indent := strings.Repeat("  ", depth)
+	status := fmt.Sprintf("%s, %d/%d restarts per %s", s.Strategy, s.Restarts, s.MaxRestarts, s.RestartWindow)
+	if s.Err != "" {
+		status += ", stopped: " + s.Err
+	} else if s.Stopped {
+		status += ", stopped"
+	}
+	fmt.Printf("%s[%s] %s\n", indent, name, status)
+	for _, w := range s.Workers {
+		uptime, next := "-", "-"
+		if w.State == "running" && !w.StartedAt.IsZero() {
+			uptime = time.Since(w.StartedAt).Round(time.Second).String()
+		}
+		if w.NextRestart != nil {
+			next = "in " + time.Until(*w.NextRestart).Round(time.Millisecond).String()
+		}
+		fmt.Printf("%-28s %-10s %-11s %8d  %-8s %-12s %s\n", indent+"  "+w.Name, w.Policy, w.State, w.Attempts, uptime, next, w.LastError)
+		if w.Supervisor != nil {
+			printSupervisor(w.Name, *w.Supervisor, depth+2)
+		}
+	}
+}
+
 // маленькая утилита без лишних зависимостей
 func ioCopy(dst *os.File, src io.Reader) {
 	buf := make([]byte, 32*1024)
@@ -215,3 +354,128 @@ func ioCopy(dst *os.File, src io.Reader) {
 		}
 	}
 }
+
+type busTopicRow struct {
+	Topic       string   `json:"topic"`
+	Subscribers int      `json:"subscribers"`
+	Published   uint64   `json:"published"`
+	Rate        float64  `json:"rate"`
+	Dropped     uint64   `json:"dropped"`
+	Retained    bool     `json:"retained"`
+	Exporters   []string `json:"exporters"`
+	Importers   []string `json:"importers"`
+}
+
+func cmdBusTopics(args []string) {
+	fs := flag.NewFlagSet("bus topics", flag.ExitOnError)
+	httpURL := fs.String("http", defaultHTTP(), "Base URL admin HTTP")
+	raw := fs.Bool("json", false, "Raw JSON")
+	_ = fs.Parse(args)
+
+	resp, err := http.Get(strings.TrimRight(*httpURL, "/") + "/admin/bus/topics")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "http error:", err)
+		return
+	}
+	defer resp.Body.Close()
+	if *raw || resp.StatusCode != http.StatusOK {
+		ioCopy(os.Stdout, resp.Body)
+		return
+	}
+	var out struct {
+		Topics []busTopicRow `json:"topics"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		fmt.Fprintln(os.Stderr, "decode error:", err)
+		return
+	}
+	list := func(ids []string) string {
+		if len(ids) == 0 {
+			return "-"
+		}
+		return strings.Join(ids, ",")
+	}
+	fmt.Printf("%-40s %5s %8s %10s %8s  %-24s %s\n", "TOPIC", "SUBS", "RATE/s", "PUBLISHED", "DROPPED", "EXPORTERS", "IMPORTERS")
+	for _, t := range out.Topics {
+		topic := t.Topic
+		if t.Retained {
+			topic += " (retained)"
+		}
+		fmt.Printf("%-40s %5d %8.2f %10d %8d  %-24s %s\n", topic, t.Subscribers, t.Rate, t.Published, t.Dropped, list(t.Exporters), list(t.Importers))
+	}
+}
+
+type tapMessage struct {
+	Topic      string            `json:"topic"`
+	Type       string            `json:"type"`
+	Key        string            `json:"key"`
+	Headers    map[string]string `json:"headers"`
+	OccurredAt time.Time         `json:"occurred_at"`
+	Payload    json.RawMessage   `json:"payload"`
+}
+
+func cmdBusTap(args []string) {
+	// шаблоны идут до флагов: rkctl bus tap 'telemetry.>' --http URL
+	var patterns []string
+	for len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		patterns, args = append(patterns, args[0]), args[1:]
+	}
+	fs := flag.NewFlagSet("bus tap", flag.ExitOnError)
+	httpURL := fs.String("http", defaultHTTP(), "Base URL admin HTTP")
+	pretty := fs.Bool("pretty", false, "Pretty JSON in payload")
+	_ = fs.Parse(args)
+	patterns = append(patterns, fs.Args()...)
+	if len(patterns) == 0 {
+		fmt.Fprintln(os.Stderr, "topic pattern is required (e.g. 'telemetry.>')")
+		return
+	}
+
+	q := make([]string, 0, len(patterns))
+	for _, p := range patterns {
+		q = append(q, "topic="+urlQueryEsc(p))
+	}
+	resp, err := http.Get(strings.TrimRight(*httpURL, "/") + "/admin/bus/tap?" + strings.Join(q, "&"))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "http error:", err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		ioCopy(os.Stderr, resp.Body)
+		return
+	}
+
+	rd := bufio.NewReader(resp.Body)
+	event := ""
+	for {
+		line, err := rd.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "event: ") {
+			event = strings.TrimPrefix(line, "event: ")
+			continue
+		}
+		if event != "message" || !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		var m tapMessage
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &m); err != nil {
+			continue
+		}
+		payload := string(m.Payload)
+		if *pretty && len(m.Payload) > 0 {
+			var j any
+			if json.Unmarshal(m.Payload, &j) == nil {
+				b, _ := json.MarshalIndent(j, "", "  ")
+				payload = "\n" + string(b)
+			}
+		}
+		key := m.Key
+		if key == "" {
+			key = "-"
+		}
+		fmt.Printf("%s %s %s %s %s\n", m.OccurredAt.Format(time.RFC3339), m.Topic, m.Type, key, payload)
+	}
+}
@@ -0,0 +1,118 @@
+package site
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"example.com/ffp/platform/contracts"
+	"example.com/ffp/platform/ports"
+	rt "example.com/ffp/platform/runtime"
+)
+
+func init() {
+	rt.RegisterFunctionFactory("site.echo", func(id string) rt.KernelModule { return NewEcho(id) })
+}
+
+// Echo — пример function-ядра: пересылает сообщения из топика in в топик out.
+type Echo struct {
+	rt.KernelModuleBase
+
+	id      string
+	host    rt.KernelHost
+	in, out string
+
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu     sync.Mutex
+	health contracts.Health
+}
+
+// NewEcho создаёт function-ядро "site.echo".
+func NewEcho(id string) *Echo {
+	return &Echo{
+		id:     id,
+		in:     "site.echo.in",
+		out:    "site.echo.out",
+		health: contracts.Health{Status: contracts.HealthStopped, Since: time.Now()},
+	}
+}
+
+func (e *Echo) Manifest() contracts.Manifest {
+	return contracts.Manifest{KernelID: e.id, Version: "0.0.1", Scope: contracts.FunctionScope}
+}
+
+// Exports — echo публикует в out (rt.ContractDeclarer).
+func (e *Echo) Exports() *contracts.Exports {
+	return &contracts.Exports{Events: []contracts.EventSpec{{Topic: e.out, Description: "копия сообщений из in"}}}
+}
+
+// Imports — echo слушает in.
+func (e *Echo) Imports() *contracts.Imports {
+	return &contracts.Imports{Events: []contracts.TopicRef{{Topic: e.in}}}
+}
+
+func (e *Echo) OnLoad(ctx context.Context, host rt.KernelHost) error {
+	e.host = host
+	return nil
+}
+
+func (e *Echo) OnConfigure(ctx context.Context, cfg map[string]any) error {
+	if v, ok := cfg["in"].(string); ok && v != "" {
+		e.in = v
+	}
+	if v, ok := cfg["out"].(string); ok && v != "" {
+		e.out = v
+	}
+	return nil
+}
+
+func (e *Echo) OnStart(ctx context.Context) error {
+	bus := e.host.EventBus()
+	ch, unsub, err := bus.Subscribe(context.Background(), e.in, ports.WithSubscriber(e.host.ID()))
+	if err != nil {
+		return err
+	}
+	runCtx, cancel := context.WithCancel(context.Background())
+	e.cancel, e.done = cancel, make(chan struct{})
+	go func() {
+		defer close(e.done)
+		defer unsub()
+		for {
+			select {
+			case <-runCtx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				_ = bus.Publish(runCtx, e.out, msg)
+			}
+		}
+	}()
+	e.setHealth(contracts.HealthReady)
+	e.host.Logger().Log(ctx, "INFO", "echo started", map[string]any{"in": e.in, "out": e.out})
+	return nil
+}
+
+func (e *Echo) OnStop(ctx context.Context) error {
+	if e.cancel != nil {
+		e.cancel()
+		<-e.done
+	}
+	e.setHealth(contracts.HealthStopped)
+	return nil
+}
+
+func (e *Echo) setHealth(s contracts.HealthStatus) {
+	e.mu.Lock()
+	e.health = contracts.Health{Status: s, Since: time.Now()}
+	e.mu.Unlock()
+}
+
+func (e *Echo) Health() contracts.Health {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.health
+}
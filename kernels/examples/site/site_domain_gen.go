@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"example.com/ffp/kernels/infra/log-forwarder"
@@ -19,9 +20,14 @@ type Domain struct {
 	host     rt.KernelHost
 	logger   ports.Logger
 	sup      *rt.Supervisor
+	funcs    *rt.FunctionManager
+	specs    []rt.FunctionSpec
 	httpAddr string
 	logGW    string
+	backoff  rt.Backoff // паузы рестартов воркеров (nil — по умолчанию супервизора)
+	fwdRetry rt.Backoff // паузы reconnect log-forwarder (nil — по умолчанию форвардера)
 
+	mu     sync.Mutex
 	health contracts.Health
 }
 
@@ -36,6 +42,14 @@ func NewDomain(id string) *Domain {
 	}
 }
 
+// Imports — log-forwarder домена слушает scope-темы логов (rt.ContractDeclarer).
+func (d *Domain) Imports() *contracts.Imports {
+	return &contracts.Imports{Events: []contracts.TopicRef{{Topic: rt.TopicTelemetryLogsScoped}}}
+}
+
+// Exports — событий домен не публикует (логи идут через tee logger хоста).
+func (d *Domain) Exports() *contracts.Exports { return &contracts.Exports{} }
+
 func (d *Domain) Manifest() contracts.Manifest {
 	return contracts.Manifest{
 		KernelID: d.id, Version: "0.0.1", Scope: contracts.DomainScope,
@@ -46,6 +60,7 @@ func (d *Domain) Manifest() contracts.Manifest {
 func (d *Domain) OnLoad(ctx context.Context, host rt.KernelHost) error {
 	d.host = host
 	d.logger = host.Logger()
+	d.funcs = rt.NewFunctionManager(host)
 	return nil
 }
 
@@ -56,74 +71,188 @@ func (d *Domain) OnConfigure(ctx context.Context, cfg map[string]any) error {
 	if v, ok := cfg["log_gateway"].(string); ok && v != "" {
 		d.logGW = v
 	}
+	specs, err := parseFunctions(cfg["functions"])
+	if err != nil {
+		return err
+	}
+	d.specs = specs
+	if d.backoff, err = parseBackoff(cfg["backoff"]); err != nil {
+		return err
+	}
+	if d.fwdRetry, err = parseBackoff(cfg["forwarder_backoff"]); err != nil {
+		return fmt.Errorf("forwarder_backoff: %w", err)
+	}
 	return nil
 }
 
-func (d *Domain) OnStart(ctx context.Context) error {
-	d.sup = rt.NewSupervisor(rt.WithOnEvent(func(ev rt.Event) {
-		// при желании можно логировать события супервизора
-	}))
+// parseBackoff разбирает {strategy, min, max, factor, jitter, step}; отсутствие ключа — nil.
+func parseBackoff(v any) (rt.Backoff, error) {
+	if v == nil {
+		return nil, nil
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("backoff: expected map, got %T", v)
+	}
+	spec, err := rt.ParseBackoff(m)
+	if err != nil {
+		return nil, err
+	}
+	return spec.Build(nil)
+}
 
-	// FK: hello (HTTP GET /hello)
-	d.sup.Start(rt.WorkerSpec{
-		Name:   "fk-hello-http",
-		Policy: rt.Permanent,
-		Fn: func(ctx context.Context) error {
-			mux := http.NewServeMux()
-			mux.HandleFunc("/hello", func(w http.ResponseWriter, r *http.Request) {
-				fmt.Fprintf(w, "hello from site: %s\n", d.id)
-			})
-			srv := &http.Server{Addr: d.httpAddr, Handler: mux}
-			go func() {
-				<-ctx.Done()
-				_ = srv.Shutdown(context.Background())
-			}()
-			d.logger.Log(ctx, "INFO", "http hello listening", map[string]any{"addr": d.httpAddr})
-			err := srv.ListenAndServe()
-			if err == http.ErrServerClosed {
-				return nil
-			}
-			return err
-		},
-	})
+// parseFunctions разбирает список functions: [{id, kind, config}].
+func parseFunctions(v any) ([]rt.FunctionSpec, error) {
+	if v == nil {
+		return nil, nil
+	}
+	list, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("functions: expected list, got %T", v)
+	}
+	out := make([]rt.FunctionSpec, 0, len(list))
+	for i, item := range list {
+		m, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("functions[%d]: expected map, got %T", i, item)
+		}
+		spec := rt.FunctionSpec{}
+		spec.ID, _ = m["id"].(string)
+		spec.Kind, _ = m["kind"].(string)
+		spec.Config, _ = m["config"].(map[string]any)
+		if spec.ID == "" || spec.Kind == "" {
+			return nil, fmt.Errorf("functions[%d]: id and kind are required", i)
+		}
+		out = append(out, spec)
+	}
+	return out, nil
+}
 
+func (d *Domain) OnStart(ctx context.Context) error {
+	d.sup = rt.NewSupervisor(
+		rt.WithStrategy(rt.OneForOne),
+		rt.WithIntensity(rt.RestartIntensity{Max: 5, Window: time.Minute}),
+		rt.WithBackoff(d.backoff), // nil — по умолчанию
+		rt.WithOnEvent(d.logSupervisorEvent),
+	)
+	d.host.ExposeSupervisor("main", d.sup) // GET /admin/kernels/{id}/workers
+	// супервизор сдался (слишком частые рестарты) — домен уходит в failed
+	go func(sup *rt.Supervisor) {
+		<-sup.Done()
+		if err := sup.Err(); err != nil {
+			d.logger.Log(context.Background(), "ERROR", "site supervisor gave up", map[string]any{"err": err.Error()})
+			d.setHealth(contracts.Health{Status: contracts.HealthFailed, Reason: err.Error(), Since: time.Now()})
+		}
+	}(d.sup)
+
+	// Дерево: log-forwarder запускается первым и останавливается последним,
+	// чтобы успеть переслать логи остановки HTTP и воркеров.
 	// FK: log-forwarder — пересылка логов домена в Root LogGateway
 	d.sup.Start(rt.WorkerSpec{
 		Name:   "fk-log-forwarder",
 		Policy: rt.Permanent,
 		Fn: func(ctx context.Context) error {
-			fwd := logforwarder.New(d.logGW, d.host.EventBus(), d.logger)
+			fwd := logforwarder.New(d.logGW, d.host.EventBus(), d.logger, logforwarder.WithBackoff(d.fwdRetry))
 			return fwd.Run(ctx) // блокирует до ctx.Done()
 		},
 	})
 
-	// Воркер: периодическая генерация тест-логов
-	d.sup.Start(rt.WorkerSpec{
-		Name:   "worker-logs",
-		Policy: rt.Permanent,
-		Fn: func(ctx context.Context) error {
-			t := time.NewTicker(3 * time.Second)
-			defer t.Stop()
-			for {
-				select {
-				case <-ctx.Done():
+	// Вложенный супервизор: heartbeat, затем HTTP (останавливаются в обратном порядке)
+	d.sup.Start(rt.SupervisorSpec("site-workers", func(sup *rt.Supervisor) error {
+		// Воркер: периодическая генерация тест-логов
+		if err := sup.Start(rt.WorkerSpec{
+			Name:   "worker-logs",
+			Policy: rt.Permanent,
+			Fn: func(ctx context.Context) error {
+				t := time.NewTicker(3 * time.Second)
+				defer t.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return nil
+					case <-t.C:
+						d.logger.Log(ctx, "INFO", "site heartbeat", map[string]any{"component": "site/heartbeat"})
+					}
+				}
+			},
+		}); err != nil {
+			return err
+		}
+
+		// FK: hello (HTTP GET /hello)
+		return sup.Start(rt.WorkerSpec{
+			Name:     "fk-hello-http",
+			Policy:   rt.Permanent,
+			Shutdown: 10 * time.Second,
+			Fn: func(ctx context.Context) error {
+				mux := http.NewServeMux()
+				mux.HandleFunc("/hello", func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprintf(w, "hello from site: %s\n", d.id)
+				})
+				srv := &http.Server{Addr: d.httpAddr, Handler: mux}
+				go func() {
+					<-ctx.Done()
+					sctx, cancel := context.WithTimeout(context.Background(), 8*time.Second)
+					defer cancel()
+					_ = srv.Shutdown(sctx)
+				}()
+				d.logger.Log(ctx, "INFO", "http hello listening", map[string]any{"addr": d.httpAddr})
+				err := srv.ListenAndServe()
+				if err == http.ErrServerClosed {
 					return nil
-				case <-t.C:
-					d.logger.Log(ctx, "INFO", "site heartbeat", map[string]any{"component": "site/heartbeat"})
 				}
-			}
-		},
-	})
+				return err
+			},
+		})
+	}, rt.WithBackoff(d.backoff), rt.WithOnEvent(d.logSupervisorEvent)))
+
+	// FK-ядра со своим FSM: ошибка любого из них роняет старт домена
+	for _, spec := range d.specs {
+		if err := d.funcs.Load(ctx, spec); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
 func (d *Domain) OnStop(ctx context.Context) error {
+	if err := d.funcs.StopAll(ctx); err != nil {
+		d.logger.Log(ctx, "WARN", "stop functions", map[string]any{"err": err.Error()})
+	}
 	if d.sup != nil {
-		d.sup.StopAll()
-		d.sup.Wait()
+		d.sup.StopAll() // HTTP → heartbeat → log-forwarder, каждый со своим лимитом ожидания
 	}
 	return nil
 }
 
-func (d *Domain) Health() contracts.Health { return d.health }
+// logSupervisorEvent пишет события супервизоров в лог домена (restart/panic видны в rkctl logs).
+func (d *Domain) logSupervisorEvent(ev rt.Event) {
+	level := "DEBUG"
+	switch ev.Type {
+	case rt.EventRestart, rt.EventStopTimeout:
+		level = "WARN"
+	case rt.EventPanic, rt.EventShutdown:
+		level = "ERROR"
+	}
+	fields := map[string]any{"component": "site/supervisor", "worker": ev.Worker, "event": string(ev.Type), "attempt": ev.Attempt}
+	if ev.Err != nil {
+		fields["err"] = ev.Err.Error()
+	}
+	if ev.NextAfter > 0 {
+		fields["next_after"] = ev.NextAfter.String()
+	}
+	d.logger.Log(context.Background(), level, "supervisor "+string(ev.Type), fields)
+}
+
+func (d *Domain) setHealth(h contracts.Health) {
+	d.mu.Lock()
+	d.health = h
+	d.mu.Unlock()
+}
+
+func (d *Domain) Health() contracts.Health {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.health
+}
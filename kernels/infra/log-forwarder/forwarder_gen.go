@@ -3,6 +3,7 @@ package logforwarder
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"google.golang.org/grpc"
@@ -20,12 +21,13 @@ type Forwarder struct {
 	bus     ports.EventBus
 	logger  ports.Logger
 	topics  []string
-	backoff rt.BackoffPolicy
+	backoff rt.Backoff
 }
 
 type Option func(*Forwarder)
 
-// WithTopics переопределяет список тем (по умолчанию — все telemetry.logs.* для домена/функций).
+// WithTopics переопределяет список тем/шаблонов (по умолчанию telemetry.logs.*).
+// Запись приходит один раз, даже если подходит под несколько шаблонов.
 func WithTopics(t []string) Option {
 	return func(f *Forwarder) {
 		if len(t) > 0 {
@@ -34,8 +36,14 @@ func WithTopics(t []string) Option {
 	}
 }
 
-// WithBackoff задаёт стратегию reconnect.
-func WithBackoff(b rt.BackoffPolicy) Option { return func(f *Forwarder) { f.backoff = b } }
+// WithBackoff задаёт стратегию reconnect (любая rt.Backoff; nil — без изменений).
+func WithBackoff(b rt.Backoff) Option {
+	return func(f *Forwarder) {
+		if b != nil {
+			f.backoff = b
+		}
+	}
+}
 
 // New создаёт форвардер. addr — адрес gRPC LogGateway (например, "127.0.0.1:8079").
 func New(addr string, bus ports.EventBus, logger ports.Logger, opts ...Option) *Forwarder {
@@ -43,11 +51,8 @@ func New(addr string, bus ports.EventBus, logger ports.Logger, opts ...Option) *
 		addr:   addr,
 		bus:    bus,
 		logger: logger,
-		topics: []string{
-			rt.TopicTelemetryLogsAll,      // общий
-			rt.TopicTelemetryLogsDomain,   // доменные
-			rt.TopicTelemetryLogsFunction, // функциональные
-		},
+		// каждая запись TeeLogger-а есть ровно в одной scope-теме: domain, function, root
+		topics:  []string{rt.TopicTelemetryLogsScoped},
 		backoff: rt.BackoffPolicy{Min: 200 * time.Millisecond, Max: 10 * time.Second, Factor: 2.0, Jitter: 0.2},
 	}
 	for _, o := range opts {
@@ -63,32 +68,22 @@ func (f *Forwarder) Run(ctx context.Context) error {
 		return errors.New("forwarder: bus or addr missing")
 	}
 
-	// объединяем несколько подписок в один входной канал
-	type anyMsg = any
-	in := make(chan anyMsg, 256)
-	cancels := make([]func(), 0, len(f.topics))
-	for _, t := range f.topics {
-		ch, cancel, _ := f.bus.Subscribe(ctx, t)
-		cancels = append(cancels, cancel)
-		go func(c <-chan anyMsg) {
-			for m := range c {
-				select {
-				case in <- m:
-				default:
-					// drop при переполнении
-				}
-			}
-		}(ch)
+	// одна подписка на все темы: шина не дублирует запись, подошедшую под несколько шаблонов
+	in, unsubscribe, err := ports.SubscribeAll(ctx, f.bus, f.topics,
+		ports.WithSubscriber("log-forwarder"),
+		ports.WithBufferSize(256),
+	)
+	if err != nil {
+		return fmt.Errorf("forwarder: subscribe: %w", err)
 	}
-	defer func() {
-		for _, c := range cancels {
-			if c != nil {
-				c()
-			}
-		}
-	}()
+	defer unsubscribe()
 
 	attempt := 0
+	var prev time.Duration // предыдущая пауза (для decorrelated)
+	pause := func(n int) time.Duration {
+		prev = f.backoff.Next(n, prev)
+		return prev
+	}
 	for {
 		attempt++
 		// dial
@@ -96,7 +91,7 @@ func (f *Forwarder) Run(ctx context.Context) error {
 		conn, err := grpc.DialContext(dctx, f.addr, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
 		cancel()
 		if err != nil {
-			sleep := f.backoff.Duration(attempt)
+			sleep := pause(attempt)
 			f.log(ctx, "WARN", "log-forwarder dial failed", map[string]any{"addr": f.addr, "err": err.Error(), "sleep": sleep.String()})
 			select {
 			case <-time.After(sleep):
@@ -105,13 +100,13 @@ func (f *Forwarder) Run(ctx context.Context) error {
 				return ctx.Err()
 			}
 		}
-		attempt = 0 // сбрасываем backoff после успешного диала
+		attempt, prev = 0, 0 // сбрасываем backoff после успешного диала
 
 		client := telemetrypb.NewLogGatewayClient(conn)
 		stream, err := client.PushLogs(ctx)
 		if err != nil {
 			_ = conn.Close()
-			sleep := f.backoff.Duration(1)
+			sleep := pause(1)
 			f.log(ctx, "WARN", "log-forwarder stream open failed", map[string]any{"err": err.Error(), "sleep": sleep.String()})
 			select {
 			case <-time.After(sleep):
@@ -130,9 +125,9 @@ func (f *Forwarder) Run(ctx context.Context) error {
 				case <-ctx.Done():
 					_ = stream.CloseSend()
 					return
-				case m := <-in:
-					if m == nil {
-						continue
+				case m, ok := <-in:
+					if !ok {
+						return
 					}
 					rec, ok := m.(telemetry.LogRecordV2)
 					if !ok {
@@ -162,7 +157,7 @@ func (f *Forwarder) Run(ctx context.Context) error {
 			}
 			// reconnect с backoff
 			attempt++
-			sleep := f.backoff.Duration(attempt)
+			sleep := pause(attempt)
 			f.log(ctx, "WARN", "log-forwarder send failed", map[string]any{"err": err.Error(), "sleep": sleep.String()})
 			select {
 			case <-time.After(sleep):
@@ -179,6 +174,3 @@ func (f *Forwarder) log(ctx context.Context, level, msg string, fields map[strin
 		f.logger.Log(ctx, level, msg, fields)
 	}
 }
-
-// Duration — публичная обёртка для BackoffPolicy (удобно в логах/тестах).
-func (b BackoffPolicy) Duration(attempt int) time.Duration { return b.duration(attempt) }
@@ -0,0 +1,34 @@
+package contracts
+
+// Протокол моста шины событий Root ↔ child kernel (режим process).
+// Ребёнок подключается к Unix-сокету из RK_BUS_SOCKET; кадр — одна строка JSON с BridgeFrame.
+// Первым идёт hello ребёнка (kernel), Root отвечает hello (свой node id) или error и закрывает соединение.
+// Дальше в обе стороны идут кадры event: от ребёнка — только темы его Exports.Events,
+// к ребёнку — только темы Imports.Events.
+
+const (
+	// EnvBusSocket — путь Unix-сокета моста шины у дочернего процесса.
+	EnvBusSocket = "RK_BUS_SOCKET"
+	// HeaderVia — id шин, через которые прошёл конверт (через запятую); защищает мост от петель.
+	HeaderVia = "via"
+	// MaxBridgeHops — больше переходов между шинами конверт не делает.
+	MaxBridgeHops = 8
+)
+
+// BridgeFrameType — тип кадра моста.
+type BridgeFrameType string
+
+const (
+	BridgeHello BridgeFrameType = "hello"
+	BridgeEvent BridgeFrameType = "event"
+	BridgeError BridgeFrameType = "error"
+)
+
+// BridgeFrame — кадр моста шины.
+type BridgeFrame struct {
+	Type     BridgeFrameType `json:"type"`
+	Kernel   string          `json:"kernel,omitempty"` // hello: id отправителя
+	Topic    string          `json:"topic,omitempty"`  // event
+	Envelope *Envelope       `json:"envelope,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
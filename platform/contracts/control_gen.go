@@ -0,0 +1,93 @@
+package contracts
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Протокол управляющего канала между Root и дочерним ядром (режим process).
+// Кадр — одна строка JSON с ControlMessage. В stdout кадр помечается префиксом
+// ControlFramePrefix, в унаследованном пайпе (fd из RK_CONTROL_FD) — передаётся без префикса.
+
+const (
+	// ControlFramePrefix отличает кадр протокола от обычной строки лога в stdout.
+	ControlFramePrefix = "@rk "
+	// EnvControlFD — переменная окружения с номером fd управляющего пайпа у дочернего процесса.
+	EnvControlFD = "RK_CONTROL_FD"
+	// RemoteKernelPath — HTTP-ручка удалённого ядра (режим remote): GET отдаёт ControlMessage
+	// типа hello (manifest, exports, imports) вместе с текущим health.
+	RemoteKernelPath = "/rk/kernel"
+)
+
+// ControlMessageType — тип кадра управляющего канала.
+type ControlMessageType string
+
+const (
+	// ControlHello — handshake: паспорт ядра, экспорты и импорты. Означает готовность.
+	ControlHello ControlMessageType = "hello"
+	// ControlHealth — очередное health-обновление от ядра.
+	ControlHealth ControlMessageType = "health"
+)
+
+// ControlMessage — кадр протокола Root ↔ child kernel.
+type ControlMessage struct {
+	Type     ControlMessageType `json:"type"`
+	Manifest *Manifest          `json:"manifest,omitempty"`
+	Exports  *Exports           `json:"exports,omitempty"`
+	Imports  *Imports           `json:"imports,omitempty"`
+	Health   *Health            `json:"health,omitempty"`
+}
+
+// ParseControlFrame разбирает кадр (с префиксом ControlFramePrefix или без него).
+func ParseControlFrame(line string) (ControlMessage, error) {
+	var msg ControlMessage
+	raw := strings.TrimSpace(strings.TrimPrefix(line, ControlFramePrefix))
+	if err := json.Unmarshal([]byte(raw), &msg); err != nil {
+		return msg, fmt.Errorf("control frame: %w", err)
+	}
+	return msg, nil
+}
+
+// EncodeControlFrame формирует кадр для stdout (с префиксом и переводом строки).
+func EncodeControlFrame(msg ControlMessage) ([]byte, error) {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	out := append([]byte(ControlFramePrefix), b...)
+	return append(out, '\n'), nil
+}
+
+// Validate проверяет кадр по его типу.
+func (m ControlMessage) Validate() error {
+	switch m.Type {
+	case ControlHello:
+		if m.Manifest == nil {
+			return fmt.Errorf("hello: manifest is required")
+		}
+		if err := m.Manifest.Validate(); err != nil {
+			return fmt.Errorf("hello: %w", err)
+		}
+		if m.Exports != nil {
+			if err := m.Exports.Validate(); err != nil {
+				return fmt.Errorf("hello: %w", err)
+			}
+		}
+		if m.Imports != nil {
+			if err := m.Imports.Validate(); err != nil {
+				return fmt.Errorf("hello: %w", err)
+			}
+		}
+	case ControlHealth:
+		if m.Health == nil {
+			return fmt.Errorf("health: health is required")
+		}
+		if !m.Health.Status.Valid() {
+			return fmt.Errorf("health: unknown status %q", m.Health.Status)
+		}
+	default:
+		return fmt.Errorf("unknown control message type %q", m.Type)
+	}
+	return nil
+}
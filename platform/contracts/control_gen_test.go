@@ -0,0 +1,93 @@
+package contracts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseControlFrame(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    ControlMessageType
+		wantErr bool
+	}{
+		{"stdout frame", `@rk {"type":"health","health":{"status":"ready"}}`, ControlHealth, false},
+		{"pipe frame", `{"type":"hello","manifest":{"kernel_id":"k","version":"1.0.0","scope":"domain"}}`, ControlHello, false},
+		{"trailing newline", "@rk {\"type\":\"health\"}\r\n", ControlHealth, false},
+		{"unknown type parses", `{"type":"bye"}`, "bye", false},
+		{"not json", "@rk hello", "", true},
+		{"truncated", `@rk {"type":"health"`, "", true},
+		{"empty", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := ParseControlFrame(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && msg.Type != tt.want {
+				t.Fatalf("type = %q, want %q", msg.Type, tt.want)
+			}
+		})
+	}
+}
+
+// Кадр после EncodeControlFrame разбирается обратно без потерь.
+func TestControlFrameRoundTrip(t *testing.T) {
+	in := ControlMessage{Type: ControlHealth, Health: &Health{Status: HealthDegraded, Reason: "slow"}}
+	b, err := EncodeControlFrame(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := string(b)
+	if !strings.HasPrefix(line, ControlFramePrefix) || !strings.HasSuffix(line, "\n") {
+		t.Fatalf("frame = %q, want %q prefix and newline", line, ControlFramePrefix)
+	}
+	out, err := ParseControlFrame(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Type != in.Type || out.Health == nil || *out.Health != *in.Health {
+		t.Fatalf("round trip = %+v, want %+v", out, in)
+	}
+}
+
+func TestControlMessageValidate(t *testing.T) {
+	manifest := &Manifest{KernelID: "k", Version: "1.2.3", Scope: DomainScope}
+	tests := []struct {
+		name    string
+		msg     ControlMessage
+		wantErr string
+	}{
+		{"hello", ControlMessage{Type: ControlHello, Manifest: manifest}, ""},
+		{"hello with contracts", ControlMessage{Type: ControlHello, Manifest: manifest,
+			Exports: &Exports{Events: []EventSpec{{Topic: "orders.created"}}},
+			Imports: &Imports{Events: []TopicRef{{Topic: "prices.>"}}}}, ""},
+		{"hello without manifest", ControlMessage{Type: ControlHello}, "manifest is required"},
+		{"hello bad version", ControlMessage{Type: ControlHello, Manifest: &Manifest{KernelID: "k", Version: "v1", Scope: DomainScope}}, "not semver"},
+		{"hello bad scope", ControlMessage{Type: ControlHello, Manifest: &Manifest{KernelID: "k", Version: "1.0.0", Scope: "cluster"}}, "unknown scope"},
+		{"hello bad export", ControlMessage{Type: ControlHello, Manifest: manifest,
+			Exports: &Exports{Network: []NetworkEndpoint{{Name: "api", Address: ":80", Protocol: "udp"}}}}, "unsupported protocol"},
+		{"hello bad import", ControlMessage{Type: ControlHello, Manifest: manifest,
+			Imports: &Imports{Storages: []StorageRef{{Kind: "sql"}}}}, "kind and name are required"},
+		{"health", ControlMessage{Type: ControlHealth, Health: &Health{Status: HealthReady}}, ""},
+		{"health without body", ControlMessage{Type: ControlHealth}, "health is required"},
+		{"health bad status", ControlMessage{Type: ControlHealth, Health: &Health{Status: "ok"}}, "unknown status"},
+		{"unknown type", ControlMessage{Type: "bye"}, "unknown control message type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.msg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
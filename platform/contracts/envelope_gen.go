@@ -0,0 +1,21 @@
+package contracts
+
+// Заголовки Envelope, которые переносятся из входящего сообщения в исходящие (ADR-0001).
+const (
+	HeaderTraceparent = "traceparent" // W3C Trace Context
+	HeaderTenant      = "tenant"
+	HeaderZone        = "zone"
+)
+
+// HeaderContentType — формат Payload (например, application/json); выставляет кодек.
+const HeaderContentType = "content-type"
+
+// PropagatedHeaders — заголовки, которые наследуются по цепочке сообщений.
+var PropagatedHeaders = []string{HeaderTraceparent, HeaderTenant, HeaderZone}
+
+// Заголовки запрос/ответ (ports.EnvelopeBus.Request).
+const (
+	HeaderReplyTo       = "reply-to"       // тема, куда ответить
+	HeaderCorrelationID = "correlation-id" // связывает ответ с запросом
+	HeaderError         = "error"          // текст ошибки обработчика
+)
@@ -0,0 +1,47 @@
+package contracts
+
+import "fmt"
+
+// lifecycleTransitions — допустимые переходы жизненного цикла ядра.
+// Stopped — терминальное состояние; перезапуск ядра — это новый экземпляр FSM.
+var lifecycleTransitions = map[LifecycleState][]LifecycleState{
+	StateLoad:      {StateInit, StateFailed, StateStopped},
+	StateInit:      {StateConfigure, StateFailed, StateStopped},
+	StateConfigure: {StateStart, StateFailed, StateStopped},
+	StateStart:     {StateReady, StateFailed, StateStopped},
+	StateReady:     {StateDegraded, StateDraining, StateFailed, StateStopped},
+	StateDegraded:  {StateReady, StateDraining, StateFailed, StateStopped},
+	StateDraining:  {StateStopped, StateFailed},
+	StateFailed:    {StateStopped},
+	StateStopped:   nil,
+}
+
+// Valid сообщает, известно ли состояние.
+func (s LifecycleState) Valid() bool {
+	_, ok := lifecycleTransitions[s]
+	return ok
+}
+
+// CanTransition сообщает, разрешён ли переход s → to.
+func (s LifecycleState) CanTransition(to LifecycleState) bool {
+	for _, next := range lifecycleTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Transitions возвращает состояния, в которые можно перейти из s.
+func (s LifecycleState) Transitions() []LifecycleState {
+	return append([]LifecycleState(nil), lifecycleTransitions[s]...)
+}
+
+// TransitionError — попытка недопустимого перехода жизненного цикла.
+type TransitionError struct {
+	From, To LifecycleState
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("illegal lifecycle transition %s -> %s", e.From, e.To)
+}
@@ -0,0 +1,101 @@
+package contracts
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// semverRe повторяет шаблон version из schemas/manifest.schema.*.
+var semverRe = regexp.MustCompile(`^[0-9]+\.[0-9]+\.[0-9]+(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// Valid сообщает, известен ли статус.
+func (s HealthStatus) Valid() bool {
+	switch s {
+	case HealthReady, HealthDegraded, HealthFailed, HealthDraining, HealthStopped:
+		return true
+	}
+	return false
+}
+
+// Valid сообщает, известен ли уровень ядра.
+func (s Scope) Valid() bool {
+	switch s {
+	case RootScope, DomainScope, FunctionScope:
+		return true
+	}
+	return false
+}
+
+// Validate проверяет манифест по правилам manifest.schema.
+func (m Manifest) Validate() error {
+	if m.KernelID == "" {
+		return fmt.Errorf("manifest: kernel_id is required")
+	}
+	if !semverRe.MatchString(m.Version) {
+		return fmt.Errorf("manifest: version %q is not semver", m.Version)
+	}
+	if !m.Scope.Valid() {
+		return fmt.Errorf("manifest: unknown scope %q", m.Scope)
+	}
+	return nil
+}
+
+// Validate проверяет обязательные поля экспортов.
+func (e Exports) Validate() error {
+	for i, n := range e.Network {
+		if n.Name == "" || n.Address == "" {
+			return fmt.Errorf("exports.network[%d]: name and address are required", i)
+		}
+		switch n.Protocol {
+		case "http", "grpc", "tcp":
+		default:
+			return fmt.Errorf("exports.network[%d]: unsupported protocol %q", i, n.Protocol)
+		}
+	}
+	for i, ev := range e.Events {
+		if ev.Topic == "" {
+			return fmt.Errorf("exports.events[%d]: topic is required", i)
+		}
+	}
+	for i, st := range e.Streams {
+		if st.Topic == "" {
+			return fmt.Errorf("exports.streams[%d]: topic is required", i)
+		}
+	}
+	for i, c := range e.CLI {
+		if c.Name == "" {
+			return fmt.Errorf("exports.cli[%d]: name is required", i)
+		}
+	}
+	for i, l := range e.Local {
+		if l.Name == "" || l.Interface == "" {
+			return fmt.Errorf("exports.local[%d]: name and interface are required", i)
+		}
+	}
+	return nil
+}
+
+// Validate проверяет обязательные поля импортов.
+func (im Imports) Validate() error {
+	for i, r := range im.RPC {
+		if r.Name == "" {
+			return fmt.Errorf("imports.rpc[%d]: name is required", i)
+		}
+	}
+	for i, t := range im.Events {
+		if t.Topic == "" {
+			return fmt.Errorf("imports.events[%d]: topic is required", i)
+		}
+	}
+	for i, s := range im.Streams {
+		if s.Topic == "" {
+			return fmt.Errorf("imports.streams[%d]: topic is required", i)
+		}
+	}
+	for i, s := range im.Storages {
+		if s.Kind == "" || s.Name == "" {
+			return fmt.Errorf("imports.storages[%d]: kind and name are required", i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package ports
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"example.com/ffp/platform/contracts"
+	"example.com/ffp/platform/telemetry"
+)
+
+// Codec превращает значение в Payload конверта и обратно.
+type Codec interface {
+	ContentType() string
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string                { return "application/json" }
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+// JSONCodec — кодек по умолчанию.
+var JSONCodec Codec = jsonCodec{}
+
+// ErrUnknownType — для Envelope.Type (или Go-типа значения) не зарегистрирован кодек.
+var ErrUnknownType = errors.New("unknown envelope type")
+
+type codecEntry struct {
+	typ   string
+	gotyp reflect.Type
+	codec Codec
+}
+
+// CodecRegistry сопоставляет Envelope.Type с Go-типом и кодеком.
+type CodecRegistry struct {
+	mu     sync.RWMutex
+	byName map[string]codecEntry
+	byType map[reflect.Type]codecEntry
+}
+
+func NewCodecRegistry() *CodecRegistry {
+	return &CodecRegistry{byName: make(map[string]codecEntry), byType: make(map[reflect.Type]codecEntry)}
+}
+
+// Register связывает тип конверта typ с Go-типом значения sample (nil codec — JSONCodec).
+// Повторная регистрация того же typ с другим Go-типом — ошибка.
+func (r *CodecRegistry) Register(typ string, sample any, codec Codec) error {
+	if typ == "" || sample == nil {
+		return errors.New("codec registry: type and sample are required")
+	}
+	if codec == nil {
+		codec = JSONCodec
+	}
+	e := codecEntry{typ: typ, gotyp: reflect.TypeOf(sample), codec: codec}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if old, ok := r.byName[typ]; ok && old.gotyp != e.gotyp {
+		return fmt.Errorf("codec registry: type %q already registered for %s", typ, old.gotyp)
+	}
+	r.byName[typ] = e
+	r.byType[e.gotyp] = e
+	return nil
+}
+
+// RegisterType — типизированная форма Register: RegisterType[telemetry.LogRecordV2](r, "telemetry.log.v2", nil).
+func RegisterType[T any](r *CodecRegistry, typ string, codec Codec) error {
+	var zero T
+	return r.Register(typ, zero, codec)
+}
+
+// TypeOf возвращает зарегистрированный тип конверта для значения v.
+func (r *CodecRegistry) TypeOf(v any) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e, ok := r.byType[reflect.TypeOf(v)]
+	return e.typ, ok
+}
+
+// Encode кодирует v: тип конверта, Payload и content-type.
+func (r *CodecRegistry) Encode(v any) (typ string, payload []byte, contentType string, err error) {
+	r.mu.RLock()
+	e, ok := r.byType[reflect.TypeOf(v)]
+	r.mu.RUnlock()
+	if !ok {
+		return "", nil, "", fmt.Errorf("%w: %T", ErrUnknownType, v)
+	}
+	payload, err = e.codec.Marshal(v)
+	return e.typ, payload, e.codec.ContentType(), err
+}
+
+// Decode восстанавливает значение зарегистрированного Go-типа (не указатель) из конверта.
+func (r *CodecRegistry) Decode(env contracts.Envelope) (any, error) {
+	r.mu.RLock()
+	e, ok := r.byName[env.Type]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownType, env.Type)
+	}
+	ptr := reflect.New(e.gotyp)
+	if err := e.codec.Unmarshal(env.Payload, ptr.Interface()); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", env.Type, err)
+	}
+	return ptr.Elem().Interface(), nil
+}
+
+// DecodeAs — типизированная форма Decode.
+func DecodeAs[T any](r *CodecRegistry, env contracts.Envelope) (T, error) {
+	var zero T
+	v, err := r.Decode(env)
+	if err != nil {
+		return zero, err
+	}
+	t, ok := v.(T)
+	if !ok {
+		return zero, fmt.Errorf("decode %s: got %T, want %T", env.Type, v, zero)
+	}
+	return t, nil
+}
+
+// Типы сообщений платформы.
+const (
+	TypeLogRecordV2 = "telemetry.log.v2"
+	TypeHealth      = "contracts.health.v1"
+)
+
+// DefaultCodecs — общий реестр; в нём уже есть типы платформы.
+var DefaultCodecs = NewCodecRegistry()
+
+func init() {
+	_ = RegisterType[telemetry.LogRecordV2](DefaultCodecs, TypeLogRecordV2, nil)
+	_ = RegisterType[contracts.Health](DefaultCodecs, TypeHealth, nil)
+}
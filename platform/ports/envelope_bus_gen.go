@@ -0,0 +1,235 @@
+package ports
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"example.com/ffp/platform/contracts"
+)
+
+// EnvelopeBus — pub/sub на уровне конвертов поверх EventBus: ID, время и тип
+// проставляются автоматически, заголовки traceparent/tenant/zone берутся из ctx.
+type EnvelopeBus struct {
+	bus     EventBus
+	codecs  *CodecRegistry
+	headers map[string]string
+	now     func() time.Time
+}
+
+type EnvelopeBusOption func(*EnvelopeBus)
+
+// WithCodecs задаёт реестр типов (по умолчанию DefaultCodecs).
+func WithCodecs(r *CodecRegistry) EnvelopeBusOption {
+	return func(b *EnvelopeBus) {
+		if r != nil {
+			b.codecs = r
+		}
+	}
+}
+
+// WithDefaultHeaders добавляет заголовки ко всем исходящим конвертам (например, zone узла);
+// заголовки из ctx и WithHeader их перекрывают.
+func WithDefaultHeaders(h map[string]string) EnvelopeBusOption {
+	return func(b *EnvelopeBus) {
+		for k, v := range h {
+			if v != "" {
+				b.headers[k] = v
+			}
+		}
+	}
+}
+
+// WithEnvelopeTime задаёт источник OccurredAt (по умолчанию time.Now).
+func WithEnvelopeTime(now func() time.Time) EnvelopeBusOption {
+	return func(b *EnvelopeBus) {
+		if now != nil {
+			b.now = now
+		}
+	}
+}
+
+// NewEnvelopeBus оборачивает шину. По шине идут значения contracts.Envelope.
+func NewEnvelopeBus(bus EventBus, opts ...EnvelopeBusOption) *EnvelopeBus {
+	b := &EnvelopeBus{bus: bus, codecs: DefaultCodecs, headers: map[string]string{}, now: time.Now}
+	for _, o := range opts {
+		o(b)
+	}
+	return b
+}
+
+// Codecs — реестр типов шины.
+func (b *EnvelopeBus) Codecs() *CodecRegistry { return b.codecs }
+
+// EnvelopeOption дополняет исходящий конверт.
+type EnvelopeOption func(*contracts.Envelope)
+
+// WithKey задаёт ключ (партиционирование/дедупликация на стороне получателя).
+func WithKey(key string) EnvelopeOption {
+	return func(e *contracts.Envelope) { e.Key = key }
+}
+
+// WithHeader задаёт заголовок конверта.
+func WithHeader(name, value string) EnvelopeOption {
+	return func(e *contracts.Envelope) {
+		if e.Headers == nil {
+			e.Headers = map[string]string{}
+		}
+		e.Headers[name] = value
+	}
+}
+
+// Publish кодирует v зарегистрированным кодеком и публикует конверт в topic.
+func (b *EnvelopeBus) Publish(ctx context.Context, topic string, v any, opts ...EnvelopeOption) error {
+	typ, payload, ct, err := b.codecs.Encode(v)
+	if err != nil {
+		return err
+	}
+	env := contracts.Envelope{Type: typ, Payload: payload, Headers: map[string]string{contracts.HeaderContentType: ct}}
+	for _, o := range opts {
+		o(&env)
+	}
+	return b.PublishEnvelope(ctx, topic, env)
+}
+
+// PublishEnvelope публикует готовый конверт: пустые ID и OccurredAt заполняются,
+// недостающие заголовки берутся из ctx (HeadersFromContext) и WithDefaultHeaders.
+func (b *EnvelopeBus) PublishEnvelope(ctx context.Context, topic string, env contracts.Envelope) error {
+	if env.Type == "" {
+		return fmt.Errorf("envelope for %s: type is required", topic)
+	}
+	if env.ID == "" {
+		env.ID = NewEnvelopeID()
+	}
+	if env.OccurredAt.IsZero() {
+		env.OccurredAt = b.now().UTC()
+	}
+	h := make(map[string]string, len(b.headers)+len(env.Headers)+len(contracts.PropagatedHeaders))
+	for k, v := range b.headers {
+		h[k] = v
+	}
+	for k, v := range HeadersFromContext(ctx) {
+		h[k] = v
+	}
+	for k, v := range env.Headers {
+		h[k] = v
+	}
+	env.Headers = h
+	return b.bus.Publish(ctx, topic, env)
+}
+
+// Subscribe подписывает на тему/шаблон и отдаёт конверты. Голые значения, опубликованные
+// через EventBus.Publish, оборачиваются: тип — из реестра (иначе имя Go-типа), Payload — кодеком (иначе JSON).
+func (b *EnvelopeBus) Subscribe(ctx context.Context, topic string, opts ...SubscribeOption) (<-chan contracts.Envelope, func(), error) {
+	in, cancel, err := b.bus.Subscribe(ctx, topic, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	// out без буфера: буфер и политика переполнения остаются у подписки шины
+	out := make(chan contracts.Envelope)
+	stop := make(chan struct{})
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			close(stop)
+			cancel()
+		})
+	}
+	go func() {
+		defer close(out)
+		for m := range in {
+			env, ok := b.Wrap(m)
+			if !ok {
+				continue
+			}
+			select {
+			case out <- env:
+			case <-stop:
+			case <-ctx.Done():
+				unsubscribe()
+			}
+		}
+	}()
+	return out, unsubscribe, nil
+}
+
+// Wrap приводит значение из канала EventBus к конверту (см. Subscribe); ports.Message разворачивается.
+// false — значение nil.
+func (b *EnvelopeBus) Wrap(m any) (contracts.Envelope, bool) {
+	if msg, ok := m.(Message); ok {
+		m = msg.Value
+	}
+	switch v := m.(type) {
+	case contracts.Envelope:
+		return v, true
+	case *contracts.Envelope:
+		if v == nil {
+			return contracts.Envelope{}, false
+		}
+		return *v, true
+	case nil:
+		return contracts.Envelope{}, false
+	}
+	env := contracts.Envelope{ID: NewEnvelopeID(), OccurredAt: b.now().UTC(), Headers: map[string]string{}}
+	typ, payload, ct, err := b.codecs.Encode(m)
+	if err != nil {
+		typ, ct = fmt.Sprintf("%T", m), JSONCodec.ContentType()
+		if payload, err = JSONCodec.Marshal(m); err != nil {
+			return contracts.Envelope{}, false
+		}
+	}
+	env.Type, env.Payload = typ, payload
+	env.Headers[contracts.HeaderContentType] = ct
+	return env, true
+}
+
+// Decode — значение из конверта по реестру шины.
+func (b *EnvelopeBus) Decode(env contracts.Envelope) (any, error) { return b.codecs.Decode(env) }
+
+// NewEnvelopeID — случайный 128-битный ID в hex.
+func NewEnvelopeID() string {
+	var buf [16]byte
+	_, _ = rand.Read(buf[:])
+	return hex.EncodeToString(buf[:])
+}
+
+type headersKey struct{}
+
+// ContextWithHeaders добавляет заголовки в ctx: их получат конверты, опубликованные с этим ctx.
+func ContextWithHeaders(ctx context.Context, h map[string]string) context.Context {
+	merged := make(map[string]string)
+	for k, v := range HeadersFromContext(ctx) {
+		merged[k] = v
+	}
+	for k, v := range h {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, headersKey{}, merged)
+}
+
+// HeadersFromContext — заголовки, накопленные в ctx (не изменять).
+func HeadersFromContext(ctx context.Context) map[string]string {
+	if ctx == nil {
+		return nil
+	}
+	h, _ := ctx.Value(headersKey{}).(map[string]string)
+	return h
+}
+
+// PropagateHeaders переносит из входящего конверта в ctx заголовки contracts.PropagatedHeaders,
+// чтобы ответные/производные сообщения продолжили трассу и сохранили tenant и zone.
+func PropagateHeaders(ctx context.Context, env contracts.Envelope) context.Context {
+	h := make(map[string]string, len(contracts.PropagatedHeaders))
+	for _, k := range contracts.PropagatedHeaders {
+		if v, ok := env.Headers[k]; ok && v != "" {
+			h[k] = v
+		}
+	}
+	if len(h) == 0 {
+		return ctx
+	}
+	return ContextWithHeaders(ctx, h)
+}
@@ -14,7 +14,9 @@ type RPC interface {
 type EventBus interface {
 	Publish(ctx context.Context, topic string, msg any) error
 	// Subscribe возвращает канал сообщений и функцию отмены подписки.
-	Subscribe(ctx context.Context, topic string) (<-chan any, func(), error)
+	// topic может быть шаблоном: "telemetry.logs.*", "telemetry.>" (см. MatchTopic);
+	// opts — буфер и политика переполнения (см. SubscribeConfig).
+	Subscribe(ctx context.Context, topic string, opts ...SubscribeOption) (<-chan any, func(), error)
 }
 
 // Stream — durable-очередь (at-least-once), абстракция над брокерами.
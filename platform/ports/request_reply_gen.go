@@ -0,0 +1,214 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"example.com/ffp/platform/contracts"
+)
+
+var (
+	// ErrNoResponders — на тему запроса никто не подписан (или в Gather не пришло ни одного ответа).
+	ErrNoResponders = errors.New("no responders")
+	// ErrRequestTimeout — ответ не пришёл до истечения таймаута запроса.
+	ErrRequestTimeout = errors.New("request timed out")
+)
+
+const (
+	// InboxPrefix — префикс тем для ответов; у каждого запроса своя тема _inbox.<id>.
+	InboxPrefix = "_inbox."
+	// ResponderPrefix — префикс имени подписок, сделанных Respond; по нему шина считает ответчиков.
+	ResponderPrefix = "responder:"
+	// TypeReplyError — тип конверта-ответа с ошибкой обработчика (текст — в заголовке error).
+	TypeReplyError        = "ports.error.v1"
+	DefaultRequestTimeout = 5 * time.Second
+)
+
+// SubscriberCounter — шина, которая знает, сколько подписок получит публикацию в topic.
+type SubscriberCounter interface {
+	Subscribers(topic string) int
+}
+
+// ResponderCounter — шина, которая знает, сколько подписок Respond получит запрос в topic
+// (прочие подписки — tap, логи — не отвечают и не считаются); -1 — неизвестно (например,
+// запрос уходит по мосту в другой домен). Без неё Request не может сразу вернуть ErrNoResponders.
+type ResponderCounter interface {
+	Responders(topic string) int
+}
+
+// ReplyError — обработчик на стороне ответчика вернул ошибку.
+type ReplyError struct {
+	Topic   string
+	Message string
+}
+
+func (e *ReplyError) Error() string { return fmt.Sprintf("%s: %s", e.Topic, e.Message) }
+
+// ReplyErr возвращает *ReplyError, если конверт — ответ с ошибкой, иначе nil.
+func ReplyErr(topic string, env contracts.Envelope) error {
+	if env.Type != TypeReplyError {
+		return nil
+	}
+	return &ReplyError{Topic: topic, Message: env.Headers[contracts.HeaderError]}
+}
+
+type requestConfig struct {
+	timeout time.Duration
+	max     int
+	env     []EnvelopeOption
+}
+
+type RequestOption func(*requestConfig)
+
+// WithRequestTimeout задаёт таймаут вызова (по умолчанию 5s; дедлайн ctx тоже действует).
+func WithRequestTimeout(d time.Duration) RequestOption {
+	return func(c *requestConfig) {
+		if d > 0 {
+			c.timeout = d
+		}
+	}
+}
+
+// WithMaxReplies — Gather завершается, собрав n ответов, не дожидаясь дедлайна.
+func WithMaxReplies(n int) RequestOption {
+	return func(c *requestConfig) { c.max = n }
+}
+
+// WithRequestEnvelope дополняет конверт запроса (ключ, заголовки).
+func WithRequestEnvelope(opts ...EnvelopeOption) RequestOption {
+	return func(c *requestConfig) { c.env = append(c.env, opts...) }
+}
+
+// Request публикует v в topic и ждёт первый ответ. Ответ с ошибкой обработчика
+// возвращается вместе с *ReplyError.
+func (b *EnvelopeBus) Request(ctx context.Context, topic string, v any, opts ...RequestOption) (contracts.Envelope, error) {
+	replies, err := b.call(ctx, topic, v, false, opts)
+	if err != nil {
+		return contracts.Envelope{}, err
+	}
+	return replies[0], ReplyErr(topic, replies[0])
+}
+
+// Gather (scatter-gather) публикует v в topic и собирает ответы всех подписчиков до дедлайна.
+// Раньше дедлайна — если ответили все ответчики темы (шина умеет ResponderCounter) или набрано WithMaxReplies.
+// Ответы с ошибкой входят в результат; проверять их — ReplyErr.
+func (b *EnvelopeBus) Gather(ctx context.Context, topic string, v any, opts ...RequestOption) ([]contracts.Envelope, error) {
+	return b.call(ctx, topic, v, true, opts)
+}
+
+func (b *EnvelopeBus) call(parent context.Context, topic string, v any, gather bool, opts []RequestOption) ([]contracts.Envelope, error) {
+	cfg := requestConfig{timeout: DefaultRequestTimeout}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	expected := -1
+	if rc, ok := b.bus.(ResponderCounter); ok {
+		if expected = rc.Responders(topic); expected == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrNoResponders, topic)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(parent, cfg.timeout)
+	defer cancel()
+	corr := NewEnvelopeID()
+	buf := 1
+	if gather {
+		buf = 64
+	}
+	in, unsub, err := b.Subscribe(ctx, InboxPrefix+corr, WithSubscriber("reply:"+topic), WithBufferSize(buf))
+	if err != nil {
+		return nil, err
+	}
+	defer unsub()
+
+	envOpts := append(append([]EnvelopeOption(nil), cfg.env...),
+		WithHeader(contracts.HeaderReplyTo, InboxPrefix+corr),
+		WithHeader(contracts.HeaderCorrelationID, corr))
+	if err := b.Publish(ctx, topic, v, envOpts...); err != nil {
+		return nil, err
+	}
+
+	var replies []contracts.Envelope
+wait:
+	for {
+		select {
+		case env, ok := <-in:
+			if !ok {
+				break wait
+			}
+			if env.Headers[contracts.HeaderCorrelationID] != corr {
+				continue
+			}
+			replies = append(replies, env)
+			if !gather || (cfg.max > 0 && len(replies) >= cfg.max) || len(replies) == expected {
+				return replies, nil
+			}
+		case <-ctx.Done():
+			break wait
+		}
+	}
+	if err := parent.Err(); errors.Is(err, context.Canceled) {
+		return replies, err
+	}
+	switch {
+	case gather && len(replies) > 0:
+		return replies, nil
+	case gather:
+		return nil, fmt.Errorf("%w: %s (no replies in %s)", ErrNoResponders, topic, cfg.timeout)
+	default:
+		return nil, fmt.Errorf("%w: %s after %s", ErrRequestTimeout, topic, cfg.timeout)
+	}
+}
+
+// Handler обрабатывает запрос. Ответ nil без ошибки — не отвечать (например, в Gather «не моё»).
+type Handler func(ctx context.Context, req contracts.Envelope) (any, error)
+
+// Respond подписывает h на тему/шаблон и отправляет его результат в reply-to запроса.
+// Запросы обрабатываются по одному, в ctx обработчика — заголовки запроса (PropagateHeaders).
+// Сообщения без reply-to обрабатываются как обычные события.
+// Имя подписки получает префикс ResponderPrefix (по умолчанию — responder:<topic>).
+func (b *EnvelopeBus) Respond(ctx context.Context, topic string, h Handler, opts ...SubscribeOption) (func(), error) {
+	opts = append(append([]SubscribeOption(nil), opts...), func(c *SubscribeConfig) {
+		switch {
+		case c.Name == "":
+			c.Name = ResponderPrefix + topic
+		case !strings.HasPrefix(c.Name, ResponderPrefix):
+			c.Name = ResponderPrefix + c.Name
+		}
+	})
+	in, unsub, err := b.Subscribe(ctx, topic, opts...)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		for req := range in {
+			rctx := PropagateHeaders(ctx, req)
+			v, herr := h(rctx, req)
+			_ = b.Reply(rctx, req, v, herr)
+		}
+	}()
+	return unsub, nil
+}
+
+// Reply отвечает на запрос req: значением v или, если herr != nil, конвертом TypeReplyError.
+// Без reply-to в запросе (или при v == nil без ошибки) ничего не делает.
+func (b *EnvelopeBus) Reply(ctx context.Context, req contracts.Envelope, v any, herr error) error {
+	replyTo := req.Headers[contracts.HeaderReplyTo]
+	if replyTo == "" {
+		return nil
+	}
+	corr := WithHeader(contracts.HeaderCorrelationID, req.Headers[contracts.HeaderCorrelationID])
+	if herr != nil {
+		env := contracts.Envelope{Type: TypeReplyError}
+		corr(&env)
+		WithHeader(contracts.HeaderError, herr.Error())(&env)
+		return b.PublishEnvelope(ctx, replyTo, env)
+	}
+	if v == nil {
+		return nil
+	}
+	return b.Publish(ctx, replyTo, v, corr)
+}
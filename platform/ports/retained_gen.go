@@ -0,0 +1,14 @@
+package ports
+
+// Retainer — шина с «последним значением» для тем состояния (здоровье, ревизия конфига,
+// жизненный цикл): новая подписка сразу получает последнее опубликованное в такие темы сообщение.
+type Retainer interface {
+	// Retain помечает темы по шаблону как retained; запоминаются публикации после вызова.
+	Retain(pattern string) error
+	// ClearRetained забывает последние значения тем, подходящих под шаблон; возвращает, сколько забыто.
+	ClearRetained(pattern string) int
+	// Retained — последнее значение темы.
+	Retained(topic string) (any, bool)
+	// RetainedTopics — темы, для которых сейчас хранится значение (по алфавиту).
+	RetainedTopics() []string
+}
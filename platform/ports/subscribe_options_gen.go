@@ -0,0 +1,141 @@
+package ports
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// OverflowPolicy — что делать с сообщением, если буфер подписки полон.
+type OverflowPolicy string
+
+const (
+	DropNewest OverflowPolicy = "drop_newest" // отбросить новое сообщение (по умолчанию)
+	DropOldest OverflowPolicy = "drop_oldest" // вытеснить самое старое из буфера
+	Block      OverflowPolicy = "block"       // ждать места до BlockTimeout, потом отбросить
+)
+
+const (
+	DefaultSubscribeBuffer = 16
+	DefaultBlockTimeout    = time.Second
+)
+
+// ParseOverflowPolicy разбирает политику из конфига ("" — DropNewest).
+func ParseOverflowPolicy(s string) (OverflowPolicy, error) {
+	switch p := OverflowPolicy(strings.ToLower(strings.TrimSpace(s))); p {
+	case "":
+		return DropNewest, nil
+	case DropNewest, DropOldest, Block:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown overflow policy %q (want drop_newest|drop_oldest|block)", s)
+	}
+}
+
+// SubscribeConfig — параметры подписки; собирается из SubscribeOption через NewSubscribeConfig.
+type SubscribeConfig struct {
+	Name         string // кто подписан (для статистики), например "admin/logs/stream"
+	Buffer       int
+	Overflow     OverflowPolicy
+	BlockTimeout time.Duration // для Block
+	Messages     bool          // доставлять Message{Topic, Value} вместо голого значения
+}
+
+// Message — сообщение вместе с темой, в которую оно опубликовано (подписка с WithMessages).
+type Message struct {
+	Topic string
+	Value any
+}
+
+type SubscribeOption func(*SubscribeConfig)
+
+// WithSubscriber подписывает подписку именем — оно видно в статистике шины.
+func WithSubscriber(name string) SubscribeOption {
+	return func(c *SubscribeConfig) { c.Name = name }
+}
+
+// WithBufferSize задаёт размер буфера подписки (по умолчанию 16).
+func WithBufferSize(n int) SubscribeOption {
+	return func(c *SubscribeConfig) {
+		if n > 0 {
+			c.Buffer = n
+		}
+	}
+}
+
+// WithOverflow задаёт политику переполнения буфера (по умолчанию DropNewest).
+func WithOverflow(p OverflowPolicy) SubscribeOption {
+	return func(c *SubscribeConfig) {
+		if p != "" {
+			c.Overflow = p
+		}
+	}
+}
+
+// WithBlock — политика Block: публикация ждёт места в буфере не дольше timeout
+// (<= 0 — DefaultBlockTimeout), затем сообщение отбрасывается.
+func WithBlock(timeout time.Duration) SubscribeOption {
+	return func(c *SubscribeConfig) {
+		c.Overflow = Block
+		c.BlockTimeout = timeout
+	}
+}
+
+// WithMessages — подписка получает Message с конкретной темой; нужно, когда подписаны
+// шаблоном и важно, откуда пришло сообщение (мосты, отладочный tap).
+func WithMessages() SubscribeOption {
+	return func(c *SubscribeConfig) { c.Messages = true }
+}
+
+// NewSubscribeConfig применяет опции к значениям по умолчанию.
+func NewSubscribeConfig(opts ...SubscribeOption) SubscribeConfig {
+	c := SubscribeConfig{Buffer: DefaultSubscribeBuffer, Overflow: DropNewest}
+	for _, o := range opts {
+		o(&c)
+	}
+	if c.BlockTimeout <= 0 {
+		c.BlockTimeout = DefaultBlockTimeout
+	}
+	return c
+}
+
+// SubscriptionStats — счётчики одной подписки.
+type SubscriptionStats struct {
+	ID        int            `json:"id"`
+	Name      string         `json:"name,omitempty"`
+	Patterns  []string       `json:"patterns"`
+	Buffer    int            `json:"buffer"`
+	Overflow  OverflowPolicy `json:"overflow"`
+	Queued    int            `json:"queued"` // сейчас в буфере
+	Delivered uint64         `json:"delivered"`
+	Dropped   uint64         `json:"dropped"`
+}
+
+// BusStats — статистика шины: итоги с момента запуска (включая отменённые подписки)
+// и текущие подписки.
+type BusStats struct {
+	Published     uint64              `json:"published"`
+	Delivered     uint64              `json:"delivered"`
+	Dropped       uint64              `json:"dropped"`
+	Subscriptions []SubscriptionStats `json:"subscriptions"`
+}
+
+// StatsProvider — шина, отдающая статистику подписок.
+type StatsProvider interface {
+	Stats() BusStats
+}
+
+// TopicStats — одна тема шины.
+type TopicStats struct {
+	Topic       string  `json:"topic"`
+	Subscribers int     `json:"subscribers"` // подписки, которые получат публикацию (включая шаблоны)
+	Published   uint64  `json:"published"`
+	Rate        float64 `json:"rate"`    // публикаций в секунду, среднее за минуту
+	Dropped     uint64  `json:"dropped"` // потери подписчиков при публикации в эту тему
+	Retained    bool    `json:"retained,omitempty"`
+}
+
+// TopicStatsProvider — шина, отдающая статистику по темам.
+type TopicStatsProvider interface {
+	TopicStats() []TopicStats
+}
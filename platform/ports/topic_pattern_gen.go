@@ -0,0 +1,105 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Шаблоны тем в стиле NATS: токены разделяются точкой,
+// "*" — ровно один токен, ">" — один и более токенов (только последним).
+//
+//	telemetry.logs.*  — telemetry.logs.domain, но не telemetry.logs и не telemetry.logs.a.b
+//	telemetry.>       — telemetry.logs, telemetry.logs.domain, но не telemetry
+const (
+	WildcardOne  = "*"
+	WildcardTail = ">"
+)
+
+// ErrMultiSubscribe — шина не умеет подписку на несколько шаблонов одним каналом.
+var ErrMultiSubscribe = errors.New("event bus does not support multi-pattern subscriptions")
+
+// MultiSubscriber — шина, подписывающая на несколько шаблонов сразу.
+// Сообщение доставляется в подписку один раз, сколько бы её шаблонов ему ни подошло.
+type MultiSubscriber interface {
+	SubscribeAll(ctx context.Context, patterns []string, opts ...SubscribeOption) (<-chan any, func(), error)
+}
+
+// SubscribeAll подписывает на несколько шаблонов одним каналом (см. MultiSubscriber);
+// для одного шаблона годится любая EventBus.
+func SubscribeAll(ctx context.Context, bus EventBus, patterns []string, opts ...SubscribeOption) (<-chan any, func(), error) {
+	if ms, ok := bus.(MultiSubscriber); ok {
+		return ms.SubscribeAll(ctx, patterns, opts...)
+	}
+	if len(patterns) == 1 {
+		return bus.Subscribe(ctx, patterns[0], opts...)
+	}
+	return nil, nil, ErrMultiSubscribe
+}
+
+// IsWildcard сообщает, есть ли в шаблоне "*" или ">".
+func IsWildcard(pattern string) bool {
+	for _, tok := range strings.Split(pattern, ".") {
+		if tok == WildcardOne || tok == WildcardTail {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTopicPattern проверяет тему или шаблон: непустые токены, ">" только последним.
+func ValidateTopicPattern(pattern string) error {
+	if pattern == "" {
+		return errors.New("empty topic")
+	}
+	toks := strings.Split(pattern, ".")
+	for i, tok := range toks {
+		switch {
+		case tok == "":
+			return fmt.Errorf("topic %q: empty token", pattern)
+		case tok == WildcardTail && i != len(toks)-1:
+			return fmt.Errorf("topic %q: %q must be the last token", pattern, WildcardTail)
+		case tok != WildcardOne && tok != WildcardTail && strings.ContainsAny(tok, "*>"):
+			return fmt.Errorf("topic %q: wildcard must be a whole token", pattern)
+		}
+	}
+	return nil
+}
+
+// MatchTopic сообщает, подходит ли тема topic под шаблон pattern.
+func MatchTopic(pattern, topic string) bool {
+	if pattern == topic {
+		return true
+	}
+	for {
+		p, prest, pmore := strings.Cut(pattern, ".")
+		t, trest, tmore := strings.Cut(topic, ".")
+		switch {
+		case p == WildcardTail:
+			return t != ""
+		case p != WildcardOne && p != t:
+			return false
+		case !pmore || !tmore:
+			return pmore == tmore
+		}
+		pattern, topic = prest, trest
+	}
+}
+
+// PatternCovers сообщает, что каждая тема под шаблоном inner подходит и под outer
+// (telemetry.> покрывает telemetry.logs.*, а telemetry.* — нет).
+func PatternCovers(outer, inner string) bool {
+	o, in := strings.Split(outer, "."), strings.Split(inner, ".")
+	for i, tok := range o {
+		switch {
+		case tok == WildcardTail:
+			return i < len(in)
+		case i >= len(in) || in[i] == WildcardTail:
+			return false
+		case tok != WildcardOne && tok != in[i]:
+			return false
+		}
+	}
+	return len(o) == len(in)
+}
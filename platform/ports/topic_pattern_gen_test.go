@@ -0,0 +1,97 @@
+package ports
+
+import "testing"
+
+func TestMatchTopic(t *testing.T) {
+	tests := []struct {
+		pattern, topic string
+		want           bool
+	}{
+		{"a.b", "a.b", true},
+		{"a.b", "a.c", false},
+		{"a.b", "a.b.c", false},
+		{"a.b.c", "a.b", false},
+		{"*", "a", true},
+		{"*", "a.b", false},
+		{"a.*", "a.b", true},
+		{"a.*", "a", false},
+		{"a.*", "a.b.c", false},
+		{"*.b", "a.b", true},
+		{"*.b", "a.c", false},
+		{"a.*.c", "a.b.c", true},
+		{"a.*.c", "a.b.d", false},
+		{">", "a", true},
+		{">", "a.b.c", true},
+		{">", "", false},
+		{"a.>", "a.b", true},
+		{"a.>", "a.b.c", true},
+		{"a.>", "a", false},
+		{"a.>", "b.c", false},
+		{"*.>", "a.b", true},
+		{"*.>", "a", false},
+		{"telemetry.logs.*", "telemetry.logs.domain", true},
+		{"telemetry.logs.*", "telemetry.logs", false},
+	}
+	for _, tt := range tests {
+		if got := MatchTopic(tt.pattern, tt.topic); got != tt.want {
+			t.Errorf("MatchTopic(%q, %q) = %v, want %v", tt.pattern, tt.topic, got, tt.want)
+		}
+	}
+}
+
+func TestPatternCovers(t *testing.T) {
+	tests := []struct {
+		outer, inner string
+		want         bool
+	}{
+		{"a.b", "a.b", true},
+		{"a.b", "a.c", false},
+		{"a.*", "a.b", true},
+		{"a.*", "a.*", true},
+		{"a.b", "a.*", false},
+		{"a.*", "a.>", false},
+		{"a.*", "a.b.c", false},
+		{"a.>", "a.b", true},
+		{"a.>", "a.*", true},
+		{"a.>", "a.b.>", true},
+		{"a.>", "a.>", true},
+		{"a.>", "a", false},
+		{"a.b.>", "a.>", false},
+		{">", "a", true},
+		{">", ">", true},
+		{"*", ">", false},
+		{"*.b", "a.b", true},
+		{"a.b", "*.b", false},
+		{"telemetry.>", "telemetry.logs.*", true},
+		{"telemetry.*", "telemetry.logs.*", false},
+	}
+	for _, tt := range tests {
+		if got := PatternCovers(tt.outer, tt.inner); got != tt.want {
+			t.Errorf("PatternCovers(%q, %q) = %v, want %v", tt.outer, tt.inner, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTopicPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		wantErr bool
+	}{
+		{"a", false},
+		{"a.b.c", false},
+		{"a.*.c", false},
+		{"a.>", false},
+		{">", false},
+		{"", true},
+		{"a..b", true},
+		{"a.", true},
+		{"a.>.b", true},
+		{"a.b*", true},
+		{"a.>b", true},
+	}
+	for _, tt := range tests {
+		if err := ValidateTopicPattern(tt.pattern); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateTopicPattern(%q) = %v, wantErr %v", tt.pattern, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package runtime
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Backoff — стратегия паузы между попытками: рестарт воркера или процесса, reconnect, проба готовности.
+type Backoff interface {
+	// Next — пауза перед попыткой attempt (1..n); prev — предыдущая пауза этой серии (0 для первой).
+	Next(attempt int, prev time.Duration) time.Duration
+}
+
+// Rand — источник случайности для джиттера. *rand.Rand подходит, но не потокобезопасен (см. NewRand).
+type Rand interface {
+	Float64() float64
+}
+
+type lockedRand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func (l *lockedRand) Float64() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Float64()
+}
+
+// NewRand — потокобезопасный источник с заданным seed: одинаковый seed — одинаковые паузы (для тестов).
+func NewRand(seed int64) Rand {
+	return &lockedRand{r: rand.New(rand.NewSource(seed))}
+}
+
+// defaultRand — общий источник для стратегий без своего Rand.
+var defaultRand = NewRand(time.Now().UnixNano())
+
+func orDefaultRand(r Rand) Rand {
+	if r == nil {
+		return defaultRand
+	}
+	return r
+}
+
+// jitter отклоняет d случайно на ±j·d и держит результат в [lo, hi].
+func jitter(d time.Duration, j float64, r Rand, lo, hi time.Duration) time.Duration {
+	if j > 1 {
+		j = 1
+	}
+	if j > 0 {
+		d += time.Duration(float64(d) * (orDefaultRand(r).Float64()*2 - 1) * j)
+	}
+	if d < lo {
+		d = lo
+	}
+	if hi > 0 && d > hi {
+		d = hi
+	}
+	return d
+}
+
+// BackoffPolicy — экспоненциальный backoff: Min·Factor^(attempt-1), не больше Max, с джиттером.
+type BackoffPolicy struct {
+	Min    time.Duration // минимальная задержка (по умолчанию 100ms)
+	Max    time.Duration // максимальная задержка (по умолчанию 30s)
+	Factor float64       // множитель экспоненты (по умолчанию 2.0)
+	Jitter float64       // 0..1, доля случайного джиттера (0 — без джиттера)
+	Rand   Rand          // источник джиттера (nil — общий)
+}
+
+// ExponentialBackoff — синоним BackoffPolicy.
+type ExponentialBackoff = BackoffPolicy
+
+func (b BackoffPolicy) withDefaults() BackoffPolicy {
+	out := b
+	if out.Min <= 0 {
+		out.Min = 100 * time.Millisecond
+	}
+	if out.Max <= 0 {
+		out.Max = 30 * time.Second
+	}
+	if out.Factor <= 0 {
+		out.Factor = 2.0
+	}
+	if out.Jitter < 0 {
+		out.Jitter = 0
+	}
+	return out
+}
+
+func (b BackoffPolicy) Next(attempt int, _ time.Duration) time.Duration {
+	b = b.withDefaults()
+	if attempt < 1 {
+		attempt = 1
+	}
+	exp := float64(b.Min) * math.Pow(b.Factor, float64(attempt-1))
+	d := b.Max
+	if exp < float64(b.Max) {
+		d = time.Duration(exp)
+	}
+	return jitter(d, b.Jitter, b.Rand, b.Min, b.Max)
+}
+
+// Duration — пауза перед попыткой attempt (без учёта предыдущей).
+func (b BackoffPolicy) Duration(attempt int) time.Duration { return b.Next(attempt, 0) }
+
+// DecorrelatedBackoff — "decorrelated jitter": случайная пауза в [Base, 3·prev], не больше Max.
+// Паузы растут в среднем экспоненциально, но соседние клиенты не синхронизируются.
+type DecorrelatedBackoff struct {
+	Base time.Duration // минимальная пауза (по умолчанию 100ms)
+	Max  time.Duration // максимальная (по умолчанию 30s)
+	Rand Rand
+}
+
+func (b DecorrelatedBackoff) Next(_ int, prev time.Duration) time.Duration {
+	base, max := b.Base, b.Max
+	if base <= 0 {
+		base = 100 * time.Millisecond
+	}
+	if max <= 0 {
+		max = 30 * time.Second
+	}
+	if prev < base {
+		prev = base
+	}
+	hi := 3 * prev
+	if hi > max {
+		hi = max
+	}
+	if hi <= base {
+		return base
+	}
+	return base + time.Duration(orDefaultRand(b.Rand).Float64()*float64(hi-base))
+}
+
+// ConstantBackoff — одна и та же пауза Delay (по умолчанию 100ms) с джиттером.
+type ConstantBackoff struct {
+	Delay  time.Duration
+	Jitter float64
+	Rand   Rand
+}
+
+func (b ConstantBackoff) Next(int, time.Duration) time.Duration {
+	d := b.Delay
+	if d <= 0 {
+		d = 100 * time.Millisecond
+	}
+	return jitter(d, b.Jitter, b.Rand, 0, 0)
+}
+
+// LinearBackoff — Min + Step·(attempt-1), не больше Max, с джиттером.
+type LinearBackoff struct {
+	Min    time.Duration // по умолчанию 100ms
+	Step   time.Duration // по умолчанию Min
+	Max    time.Duration // по умолчанию 30s
+	Jitter float64
+	Rand   Rand
+}
+
+func (b LinearBackoff) Next(attempt int, _ time.Duration) time.Duration {
+	min, step, max := b.Min, b.Step, b.Max
+	if min <= 0 {
+		min = 100 * time.Millisecond
+	}
+	if step <= 0 {
+		step = min
+	}
+	if max <= 0 {
+		max = 30 * time.Second
+	}
+	if attempt < 1 {
+		attempt = 1
+	}
+	d := max
+	if n := time.Duration(attempt - 1); n < (max-min)/step+1 {
+		d = min + step*n
+	}
+	return jitter(d, b.Jitter, b.Rand, min, max)
+}
+
+// BackoffSpec — стратегия backoff из конфига; нулевые поля — значения по умолчанию стратегии.
+type BackoffSpec struct {
+	Strategy string // exponential (по умолчанию) | decorrelated | constant | linear
+	Min      time.Duration
+	Max      time.Duration
+	Factor   float64       // exponential
+	Jitter   float64       // exponential, constant, linear
+	Step     time.Duration // linear
+}
+
+// Build создаёт стратегию; rnd — источник джиттера (nil — общий).
+func (s BackoffSpec) Build(rnd Rand) (Backoff, error) {
+	switch strings.ToLower(strings.TrimSpace(s.Strategy)) {
+	case "", "exponential":
+		return BackoffPolicy{Min: s.Min, Max: s.Max, Factor: s.Factor, Jitter: s.Jitter, Rand: rnd}, nil
+	case "decorrelated", "decorrelated_jitter":
+		return DecorrelatedBackoff{Base: s.Min, Max: s.Max, Rand: rnd}, nil
+	case "constant":
+		return ConstantBackoff{Delay: s.Min, Jitter: s.Jitter, Rand: rnd}, nil
+	case "linear":
+		return LinearBackoff{Min: s.Min, Step: s.Step, Max: s.Max, Jitter: s.Jitter, Rand: rnd}, nil
+	default:
+		return nil, fmt.Errorf("unknown backoff strategy %q", s.Strategy)
+	}
+}
+
+// ParseBackoff разбирает стратегию из конфига ядра: {strategy, min, max, factor, jitter, step};
+// паузы — строки вида "200ms" или числа секунд.
+func ParseBackoff(m map[string]any) (BackoffSpec, error) {
+	var s BackoffSpec
+	var err error
+	if v, ok := m["strategy"].(string); ok {
+		s.Strategy = v
+	}
+	for key, dst := range map[string]*time.Duration{"min": &s.Min, "max": &s.Max, "step": &s.Step} {
+		if v, ok := m[key]; ok {
+			if *dst, err = parseDuration(v); err != nil {
+				return s, fmt.Errorf("backoff %s: %w", key, err)
+			}
+		}
+	}
+	for key, dst := range map[string]*float64{"factor": &s.Factor, "jitter": &s.Jitter} {
+		if v, ok := m[key]; ok {
+			if *dst, err = parseFloat(v); err != nil {
+				return s, fmt.Errorf("backoff %s: %w", key, err)
+			}
+		}
+	}
+	_, err = s.Build(nil)
+	return s, err
+}
+
+func parseDuration(v any) (time.Duration, error) {
+	switch x := v.(type) {
+	case string:
+		return time.ParseDuration(strings.TrimSpace(x))
+	case time.Duration:
+		return x, nil
+	default:
+		f, err := parseFloat(v)
+		return time.Duration(f * float64(time.Second)), err
+	}
+}
+
+func parseFloat(v any) (float64, error) {
+	switch x := v.(type) {
+	case float64:
+		return x, nil
+	case int:
+		return float64(x), nil
+	case int64:
+		return float64(x), nil
+	case string:
+		return strconv.ParseFloat(strings.TrimSpace(x), 64)
+	default:
+		return 0, fmt.Errorf("unsupported value %v (%T)", v, v)
+	}
+}
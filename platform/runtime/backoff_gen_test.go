@@ -0,0 +1,150 @@
+package runtime
+
+import (
+	"testing"
+	"time"
+)
+
+// fixedRand всегда отдаёт одно значение: 0.5 — без отклонения, 0 и 1 — крайние точки джиттера.
+type fixedRand float64
+
+func (r fixedRand) Float64() float64 { return float64(r) }
+
+func TestBackoffPolicyNext(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		name    string
+		b       BackoffPolicy
+		attempt int
+		want    time.Duration
+	}{
+		{"defaults first", BackoffPolicy{}, 1, 100 * ms},
+		{"defaults third", BackoffPolicy{}, 3, 400 * ms},
+		{"attempt below one", BackoffPolicy{Min: 10 * ms}, 0, 10 * ms},
+		{"factor", BackoffPolicy{Min: 10 * ms, Factor: 3}, 3, 90 * ms},
+		{"capped by max", BackoffPolicy{Min: 10 * ms, Max: 50 * ms}, 10, 50 * ms},
+		{"huge attempt", BackoffPolicy{Min: 10 * ms, Max: time.Second}, 10000, time.Second},
+		{"jitter middle", BackoffPolicy{Min: 100 * ms, Jitter: 0.5, Rand: fixedRand(0.5)}, 2, 200 * ms},
+		{"jitter up", BackoffPolicy{Min: 100 * ms, Jitter: 0.5, Rand: fixedRand(1)}, 2, 300 * ms},
+		{"jitter down", BackoffPolicy{Min: 100 * ms, Jitter: 0.5, Rand: fixedRand(0)}, 2, 100 * ms},
+		{"jitter keeps min", BackoffPolicy{Min: 100 * ms, Jitter: 1, Rand: fixedRand(0)}, 1, 100 * ms},
+		{"jitter keeps max", BackoffPolicy{Min: 100 * ms, Max: 200 * ms, Jitter: 1, Rand: fixedRand(1)}, 2, 200 * ms},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.b.Next(tt.attempt, 0); got != tt.want {
+				t.Fatalf("Next(%d) = %s, want %s", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinearBackoffNext(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		name    string
+		b       LinearBackoff
+		attempt int
+		want    time.Duration
+	}{
+		{"defaults", LinearBackoff{}, 3, 300 * ms},
+		{"step", LinearBackoff{Min: 100 * ms, Step: 50 * ms}, 3, 200 * ms},
+		{"attempt below one", LinearBackoff{Min: 100 * ms, Step: 50 * ms}, -1, 100 * ms},
+		{"capped by max", LinearBackoff{Min: 100 * ms, Step: 50 * ms, Max: 220 * ms}, 5, 220 * ms},
+		{"huge attempt", LinearBackoff{Min: ms, Step: ms, Max: time.Second}, 1 << 40, time.Second},
+		{"jitter up", LinearBackoff{Min: 100 * ms, Jitter: 0.1, Rand: fixedRand(1)}, 2, 220 * ms},
+		{"jitter down", LinearBackoff{Min: 100 * ms, Jitter: 0.1, Rand: fixedRand(0)}, 2, 180 * ms},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.b.Next(tt.attempt, 0); got != tt.want {
+				t.Fatalf("Next(%d) = %s, want %s", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecorrelatedBackoffNext(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		name string
+		b    DecorrelatedBackoff
+		prev time.Duration
+		want time.Duration
+	}{
+		{"first low", DecorrelatedBackoff{Base: 100 * ms, Rand: fixedRand(0)}, 0, 100 * ms},
+		{"first high", DecorrelatedBackoff{Base: 100 * ms, Rand: fixedRand(1)}, 0, 300 * ms},
+		{"grows from prev", DecorrelatedBackoff{Base: 100 * ms, Rand: fixedRand(0.5)}, 300 * ms, 500 * ms},
+		{"capped by max", DecorrelatedBackoff{Base: 100 * ms, Max: 400 * ms, Rand: fixedRand(1)}, time.Second, 400 * ms},
+		{"max below base", DecorrelatedBackoff{Base: 100 * ms, Max: 50 * ms, Rand: fixedRand(1)}, time.Second, 100 * ms},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.b.Next(1, tt.prev); got != tt.want {
+				t.Fatalf("Next(prev %s) = %s, want %s", tt.prev, got, tt.want)
+			}
+		})
+	}
+}
+
+// Одинаковый seed — одинаковая серия пауз, и все они в пределах стратегии.
+func TestBackoffSeededRand(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		name   string
+		build  func(Rand) Backoff
+		lo, hi time.Duration
+	}{
+		{"exponential", func(r Rand) Backoff { return BackoffPolicy{Min: 10 * ms, Max: time.Second, Jitter: 0.3, Rand: r} }, 10 * ms, time.Second},
+		{"linear", func(r Rand) Backoff { return LinearBackoff{Min: 10 * ms, Max: 200 * ms, Jitter: 0.3, Rand: r} }, 10 * ms, 200 * ms},
+		{"decorrelated", func(r Rand) Backoff { return DecorrelatedBackoff{Base: 10 * ms, Max: time.Second, Rand: r} }, 10 * ms, time.Second},
+	}
+	series := func(b Backoff) []time.Duration {
+		var out []time.Duration
+		var prev time.Duration
+		for attempt := 1; attempt <= 20; attempt++ {
+			prev = b.Next(attempt, prev)
+			out = append(out, prev)
+		}
+		return out
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := series(tt.build(NewRand(42))), series(tt.build(NewRand(42)))
+			for i := range a {
+				if a[i] != b[i] {
+					t.Fatalf("attempt %d: %s != %s with the same seed", i+1, a[i], b[i])
+				}
+				if a[i] < tt.lo || a[i] > tt.hi {
+					t.Fatalf("attempt %d: %s out of [%s, %s]", i+1, a[i], tt.lo, tt.hi)
+				}
+			}
+		})
+	}
+}
+
+func TestBackoffSpecBuild(t *testing.T) {
+	tests := []struct {
+		strategy string
+		want     Backoff
+		wantErr  bool
+	}{
+		{"", BackoffPolicy{}, false},
+		{"Exponential", BackoffPolicy{}, false},
+		{"decorrelated_jitter", DecorrelatedBackoff{}, false},
+		{" constant ", ConstantBackoff{}, false},
+		{"linear", LinearBackoff{}, false},
+		{"fibonacci", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			got, err := BackoffSpec{Strategy: tt.strategy}.Build(nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("Build = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,340 @@
+package runtime
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"example.com/ffp/platform/contracts"
+	"example.com/ffp/platform/ports"
+)
+
+const (
+	// bridgeHelloTimeout — сколько ждать hello от другой стороны.
+	bridgeHelloTimeout = 5 * time.Second
+	// bridgeBuffer — буфер подписки моста на исходящие темы.
+	bridgeBuffer = 256
+	// bridgeSubscriber — префикс имени подписки моста (bridge:<peer>).
+	bridgeSubscriber = "bridge:"
+)
+
+// BridgeTopics — темы моста из паспорта ядра: exports уходят из ядра в Root, imports — из Root в ядро.
+func BridgeTopics(ex *contracts.Exports, im *contracts.Imports) (exports, imports []string) {
+	if ex != nil {
+		for _, e := range ex.Events {
+			exports = append(exports, e.Topic)
+		}
+	}
+	if im != nil {
+		for _, t := range im.Events {
+			imports = append(imports, t.Topic)
+		}
+	}
+	return exports, imports
+}
+
+// BridgeLink — одна сторона моста шины: локальная шина, её id и темы в обе стороны.
+type BridgeLink struct {
+	Bus    ports.EventBus
+	Self   string   // id локальной шины: node id Root или kernel id ребёнка
+	Send   []string // шаблоны тем, которые уходят на другую сторону
+	Accept []string // шаблоны тем, которые принимаются с другой стороны
+	Logger ports.Logger
+	// Rejected вызывается для входящего события темы вне Accept (учёт нарушений контракта).
+	Rejected func(topic string)
+}
+
+// bridgeConn — соединение моста: кадры построчно, чтение через общий буфер (hello и поток).
+type bridgeConn struct {
+	c net.Conn
+	r *bufio.Reader
+}
+
+func newBridgeConn(c net.Conn) *bridgeConn {
+	return &bridgeConn{c: c, r: bufio.NewReaderSize(c, 64*1024)}
+}
+
+func (bc *bridgeConn) read() (contracts.BridgeFrame, error) {
+	var f contracts.BridgeFrame
+	line, err := bc.r.ReadBytes('\n')
+	if err != nil {
+		return f, err
+	}
+	if err := json.Unmarshal(line, &f); err != nil {
+		return f, fmt.Errorf("bridge frame: %w", err)
+	}
+	return f, nil
+}
+
+func (bc *bridgeConn) write(f contracts.BridgeFrame) error {
+	b, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	_, err = bc.c.Write(append(b, '\n'))
+	return err
+}
+
+// readHello ждёт первый кадр hello не дольше bridgeHelloTimeout; кадр error — отказ другой стороны.
+func (bc *bridgeConn) readHello() (string, error) {
+	_ = bc.c.SetReadDeadline(time.Now().Add(bridgeHelloTimeout))
+	defer bc.c.SetReadDeadline(time.Time{})
+	f, err := bc.read()
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case f.Type == contracts.BridgeError:
+		return "", fmt.Errorf("bridge rejected: %s", f.Error)
+	case f.Type != contracts.BridgeHello || f.Kernel == "":
+		return "", fmt.Errorf("bridge: want hello, got %q", f.Type)
+	}
+	return f.Kernel, nil
+}
+
+// AcceptBusBridge обслуживает входящее соединение моста на стороне Root: читает hello,
+// получает через link параметры моста для этого ядра (ошибка — отказ) и гоняет события до разрыва.
+func AcceptBusBridge(ctx context.Context, conn net.Conn, link func(kernel string) (BridgeLink, error)) (string, error) {
+	defer conn.Close()
+	bc := newBridgeConn(conn)
+	kernel, err := bc.readHello()
+	if err != nil {
+		return "", err
+	}
+	l, err := link(kernel)
+	if err != nil {
+		_ = bc.write(contracts.BridgeFrame{Type: contracts.BridgeError, Error: err.Error()})
+		return kernel, err
+	}
+	if err := bc.write(contracts.BridgeFrame{Type: contracts.BridgeHello, Kernel: l.Self}); err != nil {
+		return kernel, err
+	}
+	return kernel, l.serve(ctx, bc, kernel)
+}
+
+// serve гоняет события в обе стороны, пока соединение живо и ctx не отменён.
+func (l BridgeLink) serve(ctx context.Context, bc *bridgeConn, peer string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		_ = bc.c.Close()
+	}()
+
+	eb := ports.NewEnvelopeBus(l.Bus)
+	errc := make(chan error, 2)
+	if len(l.Send) > 0 {
+		ch, unsub, err := ports.SubscribeAll(ctx, l.Bus, l.Send,
+			ports.WithSubscriber(bridgeSubscriber+peer), ports.WithBufferSize(bridgeBuffer), ports.WithMessages())
+		if err != nil {
+			return err
+		}
+		defer unsub()
+		go func() { errc <- l.pumpOut(ctx, bc, eb, ch, peer) }()
+	}
+	go func() { errc <- l.pumpIn(ctx, bc, eb, peer) }()
+	err := <-errc
+	if ctx.Err() != nil && errors.Is(err, net.ErrClosed) {
+		err = ctx.Err()
+	}
+	return err
+}
+
+// pumpOut отправляет локальные события исходящих тем; пришедшее с той стороны назад не уходит.
+func (l BridgeLink) pumpOut(ctx context.Context, bc *bridgeConn, eb *ports.EnvelopeBus, ch <-chan any, peer string) error {
+	for v := range ch {
+		m, ok := v.(ports.Message)
+		if !ok {
+			continue // шина не поддерживает WithMessages: тема неизвестна
+		}
+		env, ok := eb.Wrap(m.Value)
+		if !ok {
+			continue
+		}
+		via := bridgeVia(env)
+		if !l.passes(via, peer) {
+			continue
+		}
+		h := make(map[string]string, len(env.Headers)+1)
+		for k, v := range env.Headers {
+			h[k] = v
+		}
+		h[contracts.HeaderVia] = strings.Join(append(via, l.Self), ",")
+		env.Headers = h
+		if err := bc.write(contracts.BridgeFrame{Type: contracts.BridgeEvent, Topic: m.Topic, Envelope: &env}); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// pumpIn публикует в локальную шину события разрешённых тем.
+func (l BridgeLink) pumpIn(ctx context.Context, bc *bridgeConn, eb *ports.EnvelopeBus, peer string) error {
+	for {
+		f, err := bc.read()
+		if err != nil {
+			return err
+		}
+		switch f.Type {
+		case contracts.BridgeEvent:
+			if f.Envelope == nil || !matchAny(l.Accept, f.Topic) {
+				l.log(ctx, "WARN", "bus bridge: topic not allowed", map[string]any{"peer": peer, "topic": f.Topic})
+				if l.Rejected != nil && f.Envelope != nil {
+					l.Rejected(f.Topic)
+				}
+				continue
+			}
+			if !l.passes(bridgeVia(*f.Envelope), "") {
+				continue
+			}
+			if err := eb.PublishEnvelope(ctx, f.Topic, *f.Envelope); err != nil {
+				l.log(ctx, "WARN", "bus bridge: publish failed", map[string]any{"peer": peer, "topic": f.Topic, "err": err.Error()})
+			}
+		case contracts.BridgeError:
+			return fmt.Errorf("bridge peer error: %s", f.Error)
+		}
+	}
+}
+
+// passes — защита от петель: конверт не возвращается в шину, через которую уже прошёл,
+// и не делает больше MaxBridgeHops переходов.
+func (l BridgeLink) passes(via []string, peer string) bool {
+	if len(via) >= contracts.MaxBridgeHops {
+		return false
+	}
+	for _, id := range via {
+		if id == l.Self || (peer != "" && id == peer) {
+			return false
+		}
+	}
+	return true
+}
+
+func (l BridgeLink) log(ctx context.Context, level, msg string, fields map[string]any) {
+	if l.Logger != nil {
+		l.Logger.Log(ctx, level, msg, fields)
+	}
+}
+
+func bridgeVia(env contracts.Envelope) []string {
+	v := env.Headers[contracts.HeaderVia]
+	if v == "" {
+		return nil
+	}
+	return strings.Split(v, ",")
+}
+
+func matchAny(patterns []string, topic string) bool {
+	for _, p := range patterns {
+		if ports.MatchTopic(p, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// BusBridge — мост на стороне дочернего ядра: подключается к Root по Unix-сокету
+// и переподключается с backoff, пока ctx не отменён.
+type BusBridge struct {
+	link    BridgeLink
+	socket  string
+	backoff Backoff
+	clock   Clock
+}
+
+type BusBridgeOption func(*BusBridge)
+
+// WithBridgeTopics задаёт темы моста: exports — из ядра в Root, imports — из Root в ядро (см. BridgeTopics).
+func WithBridgeTopics(exports, imports []string) BusBridgeOption {
+	return func(b *BusBridge) { b.link.Send, b.link.Accept = exports, imports }
+}
+
+// WithBridgeBackoff задаёт паузы между переподключениями (по умолчанию экспонента 200ms..10s).
+func WithBridgeBackoff(bo Backoff) BusBridgeOption {
+	return func(b *BusBridge) {
+		if bo != nil {
+			b.backoff = bo
+		}
+	}
+}
+
+// WithBridgeLogger задаёт логгер моста.
+func WithBridgeLogger(l ports.Logger) BusBridgeOption {
+	return func(b *BusBridge) { b.link.Logger = l }
+}
+
+// WithBridgeClock задаёт часы пауз переподключения (по умолчанию SystemClock).
+func WithBridgeClock(c Clock) BusBridgeOption {
+	return func(b *BusBridge) { b.clock = orSystemClock(c) }
+}
+
+// NewBusBridge создаёт мост локальной шины ядра kernelID к сокету Root (обычно os.Getenv(contracts.EnvBusSocket)).
+func NewBusBridge(bus ports.EventBus, kernelID, socket string, opts ...BusBridgeOption) *BusBridge {
+	b := &BusBridge{
+		link:    BridgeLink{Bus: bus, Self: kernelID},
+		socket:  socket,
+		backoff: BackoffPolicy{Min: 200 * time.Millisecond, Max: 10 * time.Second, Jitter: 0.2},
+		clock:   SystemClock,
+	}
+	for _, o := range opts {
+		o(b)
+	}
+	return b
+}
+
+// Run держит мост до отмены ctx. После разрыва или отказа Root (например, ядро ещё не
+// прошло handshake) ждёт паузу backoff; успешное подключение сбрасывает счётчик попыток.
+func (b *BusBridge) Run(ctx context.Context) error {
+	if b.socket == "" {
+		return fmt.Errorf("bus bridge: %s is not set", contracts.EnvBusSocket)
+	}
+	attempt := 0
+	var prev time.Duration
+	for {
+		connected, err := b.connect(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if connected {
+			attempt, prev = 0, 0
+		}
+		attempt++
+		sleep := b.backoff.Next(attempt, prev)
+		prev = sleep
+		fields := map[string]any{"socket": b.socket, "attempt": attempt, "retry_in": sleep.String()}
+		if err != nil {
+			fields["err"] = err.Error()
+		}
+		b.link.log(ctx, "WARN", "bus bridge disconnected", fields)
+		select {
+		case <-b.clock.After(sleep):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// connect — одна сессия моста; true — Root принял hello.
+func (b *BusBridge) connect(ctx context.Context) (bool, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", b.socket)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	bc := newBridgeConn(conn)
+	if err := bc.write(contracts.BridgeFrame{Type: contracts.BridgeHello, Kernel: b.link.Self}); err != nil {
+		return false, err
+	}
+	root, err := bc.readHello()
+	if err != nil {
+		return false, err
+	}
+	b.link.log(ctx, "INFO", "bus bridge connected", map[string]any{"root": root, "exports": b.link.Send, "imports": b.link.Accept})
+	return true, b.link.serve(ctx, bc, root)
+}
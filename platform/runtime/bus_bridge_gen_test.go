@@ -0,0 +1,231 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"example.com/ffp/platform/contracts"
+)
+
+// bridgeRoot — сторона Root для тестов: принимает соединения моста на Unix-сокете.
+type bridgeRoot struct {
+	bus      *InMemoryEventBus
+	link     BridgeLink
+	ln       net.Listener
+	sessions chan context.CancelFunc // по одному на принятое соединение
+	rejected chan string             // темы, отвергнутые по Accept
+}
+
+func newBridgeRoot(t *testing.T, socket string, send, accept []string) *bridgeRoot {
+	t.Helper()
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &bridgeRoot{bus: NewInMemoryEventBus(), ln: ln, sessions: make(chan context.CancelFunc, 8), rejected: make(chan string, 8)}
+	r.link = BridgeLink{Bus: r.bus, Self: "root", Send: send, Accept: accept,
+		Rejected: func(topic string) { r.rejected <- topic }}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			r.sessions <- cancel
+			go AcceptBusBridge(ctx, conn, func(string) (BridgeLink, error) { return r.link, nil })
+		}
+	}()
+	t.Cleanup(func() { _ = ln.Close() })
+	return r
+}
+
+// recv ждёт следующее событие подписки не дольше секунды.
+func recv(t *testing.T, ch <-chan any, what string) any {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for %s", what)
+	}
+	return nil
+}
+
+// recvBridged — как recv, но событие должно прийти через мост (конвертом).
+func recvBridged(t *testing.T, ch <-chan any, what string) contracts.Envelope {
+	t.Helper()
+	env, ok := recv(t, ch, what).(contracts.Envelope)
+	if !ok {
+		t.Fatalf("%s: want envelope from the bridge", what)
+	}
+	return env
+}
+
+// eventually ждёт cond не дольше секунды реального времени.
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// waitBridged ждёт, пока мост подпишется на исходящие темы шины: до этого события не уходят.
+func waitBridged(t *testing.T, bus *InMemoryEventBus, peer string) {
+	t.Helper()
+	eventually(t, "bridge subscription for "+peer, func() bool {
+		for _, s := range bus.Stats().Subscriptions {
+			if s.Name == "bridge:"+peer {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// startBridge запускает мост ядра k и ждёт, пока он подключится к root.
+func startBridge(t *testing.T, root *bridgeRoot, kbus *InMemoryEventBus, exports, imports []string) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	b := NewBusBridge(kbus, "k", root.ln.Addr().String(), WithBridgeTopics(exports, imports))
+	go b.Run(ctx)
+	waitBridged(t, kbus, "root")
+	waitBridged(t, root.bus, "k")
+}
+
+// События ходят в обе стороны только по объявленным темам, а пришедшее с той стороны назад не возвращается.
+func TestBusBridgeTopicsAndEcho(t *testing.T) {
+	ctx := context.Background()
+	root := newBridgeRoot(t, filepath.Join(t.TempDir(), "bus.sock"), []string{"cfg.>", "orders.>"}, []string{"orders.>"})
+	rootAll, unsub, _ := root.bus.Subscribe(ctx, ">")
+	defer unsub()
+	kbus := NewInMemoryEventBus()
+	kernelAll, unsubK, _ := kbus.Subscribe(ctx, ">")
+	defer unsubK()
+	startBridge(t, root, kbus, []string{"orders.>", "secret.>"}, []string{"cfg.>", "orders.>"})
+
+	// тема вне Accept у Root не проходит и попадает в Rejected, следующая за ней — проходит
+	_ = kbus.Publish(ctx, "secret.key", 1)
+	_ = kbus.Publish(ctx, "orders.created", 2)
+	recv(t, kernelAll, "own secret.key")
+	recv(t, kernelAll, "own orders.created")
+	select {
+	case topic := <-root.rejected:
+		if topic != "secret.key" {
+			t.Fatalf("rejected %q, want secret.key", topic)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("secret.key was not rejected")
+	}
+	env := recvBridged(t, rootAll, "orders.created at root")
+	if string(env.Payload) != "2" || env.Headers[contracts.HeaderVia] != "k" {
+		t.Fatalf("root got %s via %q, want payload 2 via k", env.Payload, env.Headers[contracts.HeaderVia])
+	}
+
+	// Root публикует импорт ядра; эхо orders.created пришло бы раньше него
+	_ = root.bus.Publish(ctx, "cfg.limit", 3)
+	recv(t, rootAll, "own cfg.limit")
+	env = recvBridged(t, kernelAll, "cfg.limit in kernel")
+	if string(env.Payload) != "3" || env.Headers[contracts.HeaderVia] != "root" {
+		t.Fatalf("kernel got %s via %q, want payload 3 via root", env.Payload, env.Headers[contracts.HeaderVia])
+	}
+	select {
+	case v := <-rootAll:
+		t.Fatalf("root got unexpected %v", v)
+	case v := <-kernelAll:
+		t.Fatalf("kernel got unexpected %v", v)
+	default:
+	}
+}
+
+func TestBridgeLinkPasses(t *testing.T) {
+	l := BridgeLink{Self: "root"}
+	hops := make([]string, contracts.MaxBridgeHops)
+	for i := range hops {
+		hops[i] = "n" + strconv.Itoa(i)
+	}
+	tests := []struct {
+		name string
+		via  string
+		peer string
+		want bool
+	}{
+		{"local event", "", "k", true},
+		{"from another kernel", "k2", "k", true},
+		{"back to its peer", "k", "k", false},
+		{"already passed self", "k2,root", "k", false},
+		{"inbound from peer", "k", "", true},
+		{"hop below limit", strings.Join(hops[:contracts.MaxBridgeHops-1], ","), "k", true},
+		{"hop limit", strings.Join(hops, ","), "k", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := contracts.Envelope{Headers: map[string]string{contracts.HeaderVia: tt.via}}
+			if got := l.passes(bridgeVia(env), tt.peer); got != tt.want {
+				t.Fatalf("passes(%q, %q) = %v, want %v", tt.via, tt.peer, got, tt.want)
+			}
+		})
+	}
+}
+
+// После разрыва мост ждёт паузу backoff на своих часах и подключается снова.
+func TestBusBridgeReconnect(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "bus.sock")
+	clock := NewFakeClock(time.Time{})
+	kbus := NewInMemoryEventBus()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b := NewBusBridge(kbus, "k", socket, WithBridgeTopics([]string{"orders.>"}, nil),
+		WithBridgeClock(clock), WithBridgeBackoff(ConstantBackoff{Delay: time.Second}))
+	done := make(chan error, 1)
+	go func() { done <- b.Run(ctx) }()
+
+	// сокета ещё нет: попытка не удалась, мост ждёт паузу
+	clock.BlockUntil(1)
+	root := newBridgeRoot(t, socket, nil, []string{"orders.>"})
+	clock.Advance(time.Second)
+	var session context.CancelFunc
+	select {
+	case session = <-root.sessions:
+	case <-time.After(time.Second):
+		t.Fatal("bridge did not connect after backoff")
+	}
+
+	// Root рвёт соединение: без Advance мост не переподключается
+	session()
+	clock.BlockUntil(1)
+	select {
+	case <-root.sessions:
+		t.Fatal("bridge reconnected before backoff elapsed")
+	default:
+	}
+	clock.Advance(time.Second)
+	select {
+	case <-root.sessions:
+	case <-time.After(time.Second):
+		t.Fatal("bridge did not reconnect after backoff")
+	}
+
+	// события снова ходят
+	rootAll, unsub, _ := root.bus.Subscribe(context.Background(), ">")
+	defer unsub()
+	waitBridged(t, kbus, "root")
+	_ = kbus.Publish(context.Background(), "orders.created", 1)
+	recvBridged(t, rootAll, "orders.created after reconnect")
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run = %v, want context.Canceled", err)
+	}
+}
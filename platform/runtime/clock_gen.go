@@ -0,0 +1,266 @@
+package runtime
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock — источник времени для компонентов рантайма: таймеры, тикеры и "сейчас".
+// SystemClock — настоящее время, FakeClock — ручное (для детерминированных тестов).
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	After(d time.Duration) <-chan time.Time
+	NewTimer(d time.Duration) Timer
+	NewTicker(d time.Duration) Ticker
+	// AfterFunc вызывает f в отдельной горутине через d.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer — аналог *time.Timer (у таймера AfterFunc канал C не срабатывает).
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// Ticker — аналог *time.Ticker.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+	Reset(d time.Duration)
+}
+
+// SystemClock — Clock поверх пакета time.
+var SystemClock Clock = systemClock{}
+
+// orSystemClock возвращает c или SystemClock, если c == nil.
+func orSystemClock(c Clock) Clock {
+	if c == nil {
+		return SystemClock
+	}
+	return c
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) Since(t time.Time) time.Duration        { return time.Since(t) }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (systemClock) NewTimer(d time.Duration) Timer         { return systemTimer{time.NewTimer(d)} }
+func (systemClock) NewTicker(d time.Duration) Ticker       { return systemTicker{time.NewTicker(d)} }
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return systemTimer{time.AfterFunc(d, f)}
+}
+
+type systemTimer struct{ t *time.Timer }
+
+func (t systemTimer) C() <-chan time.Time        { return t.t.C }
+func (t systemTimer) Stop() bool                 { return t.t.Stop() }
+func (t systemTimer) Reset(d time.Duration) bool { return t.t.Reset(d) }
+
+type systemTicker struct{ t *time.Ticker }
+
+func (t systemTicker) C() <-chan time.Time   { return t.t.C }
+func (t systemTicker) Stop()                 { t.t.Stop() }
+func (t systemTicker) Reset(d time.Duration) { t.t.Reset(d) }
+
+// FakeClock — ручные часы: время идёт только через Advance/Set.
+// Сработавшие таймеры и тикеры пишут в канал без блокировки (буфер 1), как настоящие;
+// функции AfterFunc запускаются в отдельных горутинах.
+type FakeClock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	seq     int
+	waiters []*fakeWaiter
+}
+
+type fakeWaiter struct {
+	clock  *FakeClock
+	seq    int // порядок создания: одновременные срабатывания идут в нём
+	when   time.Time
+	period time.Duration // > 0 — тикер
+	ch     chan time.Time
+	fn     func()
+	active bool
+}
+
+// NewFakeClock создаёт ручные часы, стоящие на start (нулевое — 2000-01-01 UTC).
+func NewFakeClock(start time.Time) *FakeClock {
+	if start.IsZero() {
+		start = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	c := &FakeClock{now: start}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) Since(t time.Time) time.Duration { return c.Now().Sub(t) }
+
+func (c *FakeClock) After(d time.Duration) <-chan time.Time { return c.NewTimer(d).C() }
+
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	return c.add(&fakeWaiter{ch: make(chan time.Time, 1)}, d)
+}
+
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	return c.add(&fakeWaiter{fn: f}, d)
+}
+
+func (c *FakeClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("runtime: non-positive interval for FakeClock.NewTicker")
+	}
+	return fakeTicker{c.add(&fakeWaiter{ch: make(chan time.Time, 1), period: d}, d)}
+}
+
+func (c *FakeClock) add(w *fakeWaiter, d time.Duration) *fakeWaiter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w.clock = c
+	c.schedule(w, d)
+	return w
+}
+
+// schedule ставит w на срабатывание через d; вызывается под c.mu.
+func (c *FakeClock) schedule(w *fakeWaiter, d time.Duration) {
+	c.seq++
+	w.seq, w.when, w.active = c.seq, c.now.Add(d), true
+	if d <= 0 {
+		c.fire(w)
+		return
+	}
+	c.waiters = append(c.waiters, w)
+	c.cond.Broadcast()
+}
+
+// unschedule снимает w с ожидания; вызывается под c.mu.
+func (c *FakeClock) unschedule(w *fakeWaiter) bool {
+	was := w.active
+	w.active = false
+	for i, x := range c.waiters {
+		if x == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			break
+		}
+	}
+	return was
+}
+
+// fire срабатывает w в момент w.when; вызывается под c.mu.
+func (c *FakeClock) fire(w *fakeWaiter) {
+	switch {
+	case w.fn != nil:
+		w.active = false
+		go w.fn()
+	case w.period > 0:
+		select {
+		case w.ch <- w.when:
+		default:
+		}
+		w.when = w.when.Add(w.period)
+		c.waiters = append(c.waiters, w)
+	default:
+		w.active = false
+		select {
+		case w.ch <- w.when:
+		default:
+		}
+	}
+}
+
+// Advance сдвигает время на d, по порядку срабатывая все таймеры и тикеры,
+// чей срок наступил (тикер — столько раз, сколько периодов прошло).
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.advanceTo(c.now.Add(d))
+}
+
+// Set переводит часы на t; назад время не идёт.
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.advanceTo(t)
+}
+
+func (c *FakeClock) advanceTo(t time.Time) {
+	for {
+		sort.SliceStable(c.waiters, func(i, j int) bool {
+			a, b := c.waiters[i], c.waiters[j]
+			if !a.when.Equal(b.when) {
+				return a.when.Before(b.when)
+			}
+			return a.seq < b.seq
+		})
+		if len(c.waiters) == 0 || c.waiters[0].when.After(t) {
+			break
+		}
+		w := c.waiters[0]
+		c.waiters = c.waiters[1:]
+		if w.when.After(c.now) {
+			c.now = w.when
+		}
+		c.fire(w)
+	}
+	if t.After(c.now) {
+		c.now = t
+	}
+}
+
+// Waiters — число ожидающих таймеров, тикеров и AfterFunc.
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+// BlockUntil ждёт, пока ожидающих станет не меньше n: так тест узнаёт,
+// что компонент уже взвёл таймер, и Advance его не обгонит.
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.waiters) < n {
+		c.cond.Wait()
+	}
+}
+
+func (w *fakeWaiter) C() <-chan time.Time { return w.ch }
+
+func (w *fakeWaiter) Stop() bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	return w.clock.unschedule(w)
+}
+
+func (w *fakeWaiter) Reset(d time.Duration) bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	was := w.clock.unschedule(w)
+	w.clock.schedule(w, d)
+	return was
+}
+
+type fakeTicker struct{ w *fakeWaiter }
+
+func (t fakeTicker) C() <-chan time.Time { return t.w.ch }
+func (t fakeTicker) Stop()               { t.w.Stop() }
+
+func (t fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("runtime: non-positive interval for Ticker.Reset")
+	}
+	t.w.clock.mu.Lock()
+	defer t.w.clock.mu.Unlock()
+	t.w.clock.unschedule(t.w)
+	t.w.period = d
+	t.w.clock.schedule(t.w, d)
+}
@@ -0,0 +1,140 @@
+package runtime
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// Advance срабатывает все таймеры, чей срок наступил, каждый — со своим временем срабатывания.
+func TestFakeClockAdvance(t *testing.T) {
+	c := NewFakeClock(time.Time{})
+	start := c.Now()
+	timers := map[time.Duration]Timer{}
+	for _, d := range []time.Duration{3 * time.Second, time.Second, 2 * time.Second, 5 * time.Second} {
+		timers[d] = c.NewTimer(d)
+	}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	c.AfterFunc(2*time.Second, wg.Done)
+
+	c.Advance(3 * time.Second)
+	wg.Wait()
+	for _, d := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second} {
+		if v := <-timers[d].C(); !v.Equal(start.Add(d)) {
+			t.Fatalf("%s timer fired at +%s", d, v.Sub(start))
+		}
+	}
+	select {
+	case <-timers[5*time.Second].C():
+		t.Fatal("5s timer fired after 3s")
+	default:
+	}
+	if !c.Now().Equal(start.Add(3 * time.Second)) {
+		t.Fatalf("Now = +%s, want +3s", c.Now().Sub(start))
+	}
+	if c.Waiters() != 1 {
+		t.Fatalf("Waiters = %d, want 1", c.Waiters())
+	}
+}
+
+// Тикер за один Advance срабатывает столько раз, сколько прошло периодов,
+// но, как настоящий, копит в канале не больше одного значения.
+func TestFakeClockTicker(t *testing.T) {
+	c := NewFakeClock(time.Time{})
+	start := c.Now()
+	tk := c.NewTicker(time.Second)
+	defer tk.Stop()
+
+	c.Advance(3500 * time.Millisecond)
+	if v := <-tk.C(); !v.Equal(start.Add(time.Second)) {
+		t.Fatalf("tick at %s, want first tick at +1s", v.Sub(start))
+	}
+	select {
+	case v := <-tk.C():
+		t.Fatalf("extra buffered tick at %s", v.Sub(start))
+	default:
+	}
+
+	c.Advance(500 * time.Millisecond)
+	if v := <-tk.C(); !v.Equal(start.Add(4 * time.Second)) {
+		t.Fatalf("tick at %s, want +4s", v.Sub(start))
+	}
+
+	tk.Reset(10 * time.Second)
+	c.Advance(9 * time.Second)
+	select {
+	case v := <-tk.C():
+		t.Fatalf("tick at %s before reset period elapsed", v.Sub(start))
+	default:
+	}
+	c.Advance(time.Second)
+	if v := <-tk.C(); !v.Equal(start.Add(14 * time.Second)) {
+		t.Fatalf("tick at %s, want +14s", v.Sub(start))
+	}
+}
+
+func TestFakeClockStopReset(t *testing.T) {
+	c := NewFakeClock(time.Time{})
+	tm := c.NewTimer(time.Second)
+	if !tm.Stop() {
+		t.Fatal("Stop of an active timer = false")
+	}
+	if tm.Stop() {
+		t.Fatal("second Stop = true")
+	}
+	c.Advance(time.Minute)
+	select {
+	case <-tm.C():
+		t.Fatal("stopped timer fired")
+	default:
+	}
+
+	if tm.Reset(time.Second) {
+		t.Fatal("Reset of a stopped timer = true")
+	}
+	if !tm.Reset(2 * time.Second) {
+		t.Fatal("Reset of an active timer = false")
+	}
+	c.Advance(time.Second)
+	select {
+	case <-tm.C():
+		t.Fatal("timer fired at the old deadline")
+	default:
+	}
+	c.Advance(time.Second)
+	<-tm.C()
+
+	// нулевая пауза срабатывает сразу, без Advance
+	<-c.After(0)
+	if c.Waiters() != 0 {
+		t.Fatalf("Waiters = %d, want 0", c.Waiters())
+	}
+}
+
+// BlockUntil отпускает тест только после того, как горутина взвела таймер.
+func TestFakeClockBlockUntil(t *testing.T) {
+	c := NewFakeClock(time.Time{})
+	fired := make(chan time.Duration, 1)
+	go func() {
+		start := c.Now()
+		<-c.After(time.Second)
+		fired <- c.Since(start)
+	}()
+	c.BlockUntil(1)
+	c.Advance(time.Second)
+	if d := <-fired; d != time.Second {
+		t.Fatalf("waited %s, want 1s", d)
+	}
+
+	// Set не отводит часы назад
+	now := c.Now()
+	c.Set(now.Add(-time.Hour))
+	if !c.Now().Equal(now) {
+		t.Fatalf("Set moved the clock back to %s", c.Now())
+	}
+	c.Set(now.Add(time.Hour))
+	if got := c.Since(now); got != time.Hour {
+		t.Fatalf("Since = %s, want 1h", got)
+	}
+}
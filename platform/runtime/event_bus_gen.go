@@ -0,0 +1,527 @@
+package runtime
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"example.com/ffp/platform/ports"
+)
+
+// subscriber — одна подписка: канал, её шаблоны тем и политика переполнения.
+type subscriber struct {
+	id       int
+	patterns []string
+	cfg      ports.SubscribeConfig
+	ch       chan any
+
+	mu     sync.RWMutex // отправка — под RLock, закрытие канала — под Lock
+	closed bool
+	gone   chan struct{} // закрывается при отмене: будит заблокированных отправителей
+
+	delivered atomic.Uint64
+	dropped   atomic.Uint64
+	busDrops  *atomic.Uint64 // итог по шине
+}
+
+// drop учитывает потерю; tc — тема, публикация в которую привела к потере (nil — без учёта по теме).
+func (s *subscriber) drop(tc *topicCounter) {
+	s.dropped.Add(1)
+	s.busDrops.Add(1)
+	if tc != nil {
+		tc.dropped.Add(1)
+	}
+}
+
+func (s *subscriber) match(topic string) bool {
+	for _, p := range s.patterns {
+		if ports.MatchTopic(p, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// wrap оборачивает сообщение в ports.Message, если подписка просила тему (WithMessages).
+func (s *subscriber) wrap(topic string, msg any) any {
+	if s.cfg.Messages {
+		return ports.Message{Topic: topic, Value: msg}
+	}
+	return msg
+}
+
+// send кладёт сообщение в канал по политике переполнения; true — доставлено.
+func (s *subscriber) send(ctx context.Context, msg any, tc *topicCounter) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return false, nil
+	}
+	select {
+	case s.ch <- msg:
+		return true, nil
+	default:
+	}
+	switch s.cfg.Overflow {
+	case ports.DropOldest:
+		for {
+			select {
+			case s.ch <- msg:
+				return true, nil
+			default:
+			}
+			select {
+			case <-s.ch:
+				s.drop(tc) // вытеснили самое старое
+			default:
+			}
+		}
+	case ports.Block:
+		t := time.NewTimer(s.cfg.BlockTimeout)
+		defer t.Stop()
+		select {
+		case s.ch <- msg:
+			return true, nil
+		case <-t.C:
+		case <-s.gone:
+			return false, nil
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+	s.drop(tc)
+	return false, nil
+}
+
+func (s *subscriber) stats() ports.SubscriptionStats {
+	return ports.SubscriptionStats{
+		ID:        s.id,
+		Name:      s.cfg.Name,
+		Patterns:  append([]string(nil), s.patterns...),
+		Buffer:    s.cfg.Buffer,
+		Overflow:  s.cfg.Overflow,
+		Queued:    len(s.ch),
+		Delivered: s.delivered.Load(),
+		Dropped:   s.dropped.Load(),
+	}
+}
+
+// InMemoryEventBus — in-proc шина. Точные темы ищутся по индексу,
+// шаблоны ("*", ">") проверяются при каждой публикации. Темы, помеченные Retain,
+// хранят последнее сообщение и отдают его новым подпискам (ports.Retainer).
+type InMemoryEventBus struct {
+	mu   sync.RWMutex
+	subs map[string]map[int]*subscriber // точная тема → подписки
+	wild map[int]*subscriber            // подписки, у которых есть шаблоны
+	all  map[int]*subscriber
+	next int
+
+	retain []string       // шаблоны retained-тем, под mu
+	lastMu sync.Mutex     // last меняется и под RLock публикации
+	last   map[string]any // тема → последнее сообщение
+
+	// итоги, включая отменённые подписки
+	published atomic.Uint64
+	delivered atomic.Uint64
+	dropped   atomic.Uint64
+
+	clock    Clock
+	topicMu  sync.Mutex
+	topics   map[string]*topicCounter // счётчики по темам публикаций (кроме _inbox.*)
+	prunedAt int64                    // когда (unix) из topics последний раз убирали заброшенные темы
+}
+
+type BusOption func(*InMemoryEventBus)
+
+// WithBusClock задаёт часы для скорости публикаций по темам (по умолчанию SystemClock).
+func WithBusClock(c Clock) BusOption {
+	return func(b *InMemoryEventBus) { b.clock = orSystemClock(c) }
+}
+
+func NewInMemoryEventBus(opts ...BusOption) *InMemoryEventBus {
+	b := &InMemoryEventBus{
+		subs:   make(map[string]map[int]*subscriber),
+		wild:   make(map[int]*subscriber),
+		all:    make(map[int]*subscriber),
+		last:   make(map[string]any),
+		clock:  SystemClock,
+		topics: make(map[string]*topicCounter),
+	}
+	for _, o := range opts {
+		o(b)
+	}
+	b.prunedAt = b.clock.Now().Unix()
+	return b
+}
+
+// topicRateWindow — окно, за которое считается скорость публикаций в тему.
+const topicRateWindow = 60
+
+// topicCounter — публикации и потери одной темы; скорость — по секундным корзинам за topicRateWindow секунд.
+type topicCounter struct {
+	published atomic.Uint64
+	dropped   atomic.Uint64
+
+	last atomic.Int64 // секунда (unix) последней публикации
+
+	mu      sync.Mutex
+	buckets [topicRateWindow]uint64
+	stamps  [topicRateWindow]int64 // секунда (unix), к которой относится корзина
+}
+
+func (tc *topicCounter) add(now time.Time) {
+	tc.published.Add(1)
+	sec := now.Unix()
+	tc.last.Store(sec)
+	i := sec % topicRateWindow
+	tc.mu.Lock()
+	if tc.stamps[i] != sec {
+		tc.stamps[i], tc.buckets[i] = sec, 0
+	}
+	tc.buckets[i]++
+	tc.mu.Unlock()
+}
+
+// rate — публикаций в секунду в среднем за последние topicRateWindow секунд.
+func (tc *topicCounter) rate(now time.Time) float64 {
+	sec := now.Unix()
+	var n uint64
+	tc.mu.Lock()
+	for i, st := range tc.stamps {
+		if st > sec-topicRateWindow && st <= sec {
+			n += tc.buckets[i]
+		}
+	}
+	tc.mu.Unlock()
+	return float64(n) / topicRateWindow
+}
+
+func (b *InMemoryEventBus) topicCounter(topic string) *topicCounter {
+	if strings.HasPrefix(topic, ports.InboxPrefix) {
+		return nil // у каждого запроса своя тема ответа — не копим их
+	}
+	b.topicMu.Lock()
+	defer b.topicMu.Unlock()
+	b.pruneTopicsLocked(b.clock.Now())
+	tc := b.topics[topic]
+	if tc == nil {
+		tc = &topicCounter{}
+		b.topics[topic] = tc
+	}
+	return tc
+}
+
+// pruneTopicsLocked не чаще раза в topicRateWindow забывает счётчики тем без точных подписок,
+// в которые не публиковали целое окно: иначе темы с id в имени (в том числе пойманные шаблоном)
+// копили бы счётчики без предела. Вызывается под topicMu.
+func (b *InMemoryEventBus) pruneTopicsLocked(now time.Time) {
+	sec := now.Unix()
+	if sec-b.prunedAt < topicRateWindow {
+		return
+	}
+	b.prunedAt = sec
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for t, tc := range b.topics {
+		if sec-tc.last.Load() >= topicRateWindow && len(b.subs[t]) == 0 {
+			delete(b.topics, t)
+		}
+	}
+}
+
+func (b *InMemoryEventBus) Publish(ctx context.Context, topic string, msg any) error {
+	b.published.Add(1)
+	tc := b.topicCounter(topic)
+	if tc != nil {
+		tc.add(b.clock.Now())
+	}
+	b.mu.RLock()
+	// запоминаем под той же блокировкой, что и выбор получателей: подписка видит
+	// либо это сообщение как retained, либо получает его обычной доставкой
+	if b.retained(topic) {
+		b.lastMu.Lock()
+		b.last[topic] = msg
+		b.lastMu.Unlock()
+	}
+	var targets []*subscriber
+	var seen map[int]bool
+	add := func(sub *subscriber) {
+		if len(sub.patterns) > 1 {
+			// подписка на несколько шаблонов получает сообщение один раз
+			if seen[sub.id] {
+				return
+			}
+			if seen == nil {
+				seen = make(map[int]bool)
+			}
+			seen[sub.id] = true
+		}
+		targets = append(targets, sub)
+	}
+	for _, sub := range b.subs[topic] {
+		add(sub)
+	}
+	for _, sub := range b.wild {
+		if sub.match(topic) {
+			add(sub)
+		}
+	}
+	b.mu.RUnlock()
+
+	// рассылка без блокировки шины: подписка с Block может ждать места
+	for _, sub := range targets {
+		ok, err := sub.send(ctx, sub.wrap(topic, msg), tc)
+		if ok {
+			sub.delivered.Add(1)
+			b.delivered.Add(1)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Subscribe подписывает на тему или шаблон (telemetry.logs.*, telemetry.>).
+func (b *InMemoryEventBus) Subscribe(ctx context.Context, topic string, opts ...ports.SubscribeOption) (<-chan any, func(), error) {
+	return b.SubscribeAll(ctx, []string{topic}, opts...)
+}
+
+// SubscribeAll подписывает одним каналом на несколько тем/шаблонов (ports.MultiSubscriber).
+func (b *InMemoryEventBus) SubscribeAll(ctx context.Context, patterns []string, opts ...ports.SubscribeOption) (<-chan any, func(), error) {
+	if len(patterns) == 0 {
+		return nil, nil, ports.ValidateTopicPattern("")
+	}
+	for _, p := range patterns {
+		if err := ports.ValidateTopicPattern(p); err != nil {
+			return nil, nil, err
+		}
+	}
+	cfg := ports.NewSubscribeConfig(opts...)
+	if _, err := ports.ParseOverflowPolicy(string(cfg.Overflow)); err != nil {
+		return nil, nil, err
+	}
+	ch := make(chan any, cfg.Buffer)
+
+	b.mu.Lock()
+	sub := &subscriber{id: b.next, patterns: append([]string(nil), patterns...), cfg: cfg, ch: ch, gone: make(chan struct{}), busDrops: &b.dropped}
+	b.next++
+	wild := false
+	for _, p := range sub.patterns {
+		if ports.IsWildcard(p) {
+			wild = true
+			continue
+		}
+		if b.subs[p] == nil {
+			b.subs[p] = make(map[int]*subscriber)
+		}
+		b.subs[p][sub.id] = sub
+	}
+	if wild {
+		b.wild[sub.id] = sub
+	}
+	b.all[sub.id] = sub
+	b.replay(sub)
+	b.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.wild, sub.id)
+			delete(b.all, sub.id)
+			for _, p := range sub.patterns {
+				if subs, ok := b.subs[p]; ok {
+					delete(subs, sub.id)
+					if len(subs) == 0 {
+						delete(b.subs, p)
+					}
+				}
+			}
+			b.mu.Unlock()
+
+			close(sub.gone) // отпускаем отправителей, ждущих места (Block)
+			sub.mu.Lock()
+			sub.closed = true
+			close(sub.ch)
+			sub.mu.Unlock()
+		})
+	}
+
+	go func() {
+		<-ctx.Done()
+		cancel()
+	}()
+
+	return ch, cancel, nil
+}
+
+// replay отдаёт новой подписке последние значения подходящих retained-тем (под b.mu).
+func (b *InMemoryEventBus) replay(sub *subscriber) {
+	b.lastMu.Lock()
+	defer b.lastMu.Unlock()
+	topics := make([]string, 0, len(b.last))
+	for t := range b.last {
+		if sub.match(t) {
+			topics = append(topics, t)
+		}
+	}
+	sort.Strings(topics)
+	for _, t := range topics {
+		select {
+		case sub.ch <- sub.wrap(t, b.last[t]):
+			sub.delivered.Add(1)
+			b.delivered.Add(1)
+		default:
+			sub.drop(nil)
+		}
+	}
+}
+
+func (b *InMemoryEventBus) retained(topic string) bool {
+	for _, p := range b.retain {
+		if ports.MatchTopic(p, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// Retain помечает темы по шаблону как retained (ports.Retainer).
+func (b *InMemoryEventBus) Retain(pattern string) error {
+	if err := ports.ValidateTopicPattern(pattern); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, p := range b.retain {
+		if p == pattern {
+			return nil
+		}
+	}
+	b.retain = append(b.retain, pattern)
+	return nil
+}
+
+// ClearRetained забывает последние значения тем под шаблоном; пометка Retain остаётся.
+func (b *InMemoryEventBus) ClearRetained(pattern string) int {
+	b.lastMu.Lock()
+	defer b.lastMu.Unlock()
+	n := 0
+	for t := range b.last {
+		if ports.MatchTopic(pattern, t) {
+			delete(b.last, t)
+			n++
+		}
+	}
+	return n
+}
+
+// Retained — последнее значение retained-темы.
+func (b *InMemoryEventBus) Retained(topic string) (any, bool) {
+	b.lastMu.Lock()
+	defer b.lastMu.Unlock()
+	v, ok := b.last[topic]
+	return v, ok
+}
+
+// RetainedTopics — темы с сохранённым значением.
+func (b *InMemoryEventBus) RetainedTopics() []string {
+	b.lastMu.Lock()
+	topics := make([]string, 0, len(b.last))
+	for t := range b.last {
+		topics = append(topics, t)
+	}
+	b.lastMu.Unlock()
+	sort.Strings(topics)
+	return topics
+}
+
+// Subscribers — сколько подписок получит публикацию в topic (ports.SubscriberCounter).
+func (b *InMemoryEventBus) Subscribers(topic string) int {
+	return b.count(topic, func(*subscriber) bool { return true })
+}
+
+// Responders — сколько подписок Respond получит запрос в topic (ports.ResponderCounter).
+// Если запрос уходит по мосту в другой домен, число ответчиков там неизвестно: -1.
+func (b *InMemoryEventBus) Responders(topic string) int {
+	if b.count(topic, func(s *subscriber) bool { return strings.HasPrefix(s.cfg.Name, bridgeSubscriber) }) > 0 {
+		return -1
+	}
+	return b.count(topic, func(s *subscriber) bool { return strings.HasPrefix(s.cfg.Name, ports.ResponderPrefix) })
+}
+
+func (b *InMemoryEventBus) count(topic string, ok func(*subscriber) bool) int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	n := 0
+	for _, sub := range b.subs[topic] {
+		if ok(sub) {
+			n++
+		}
+	}
+	for id, sub := range b.wild {
+		if _, exact := b.subs[topic][id]; !exact && sub.match(topic) && ok(sub) {
+			n++
+		}
+	}
+	return n
+}
+
+// TopicStats — темы шины: куда публиковали, на какие подписаны точно и retained,
+// с числом подписок, скоростью и потерями (ports.TopicStatsProvider).
+func (b *InMemoryEventBus) TopicStats() []ports.TopicStats {
+	now := b.clock.Now()
+	rows := make(map[string]*ports.TopicStats)
+	row := func(t string) *ports.TopicStats {
+		r := rows[t]
+		if r == nil {
+			r = &ports.TopicStats{Topic: t}
+			rows[t] = r
+		}
+		return r
+	}
+	b.topicMu.Lock()
+	b.pruneTopicsLocked(now)
+	for t, tc := range b.topics {
+		r := row(t)
+		r.Published, r.Dropped, r.Rate = tc.published.Load(), tc.dropped.Load(), tc.rate(now)
+	}
+	b.topicMu.Unlock()
+	b.mu.RLock()
+	for t := range b.subs {
+		row(t)
+	}
+	b.mu.RUnlock()
+	for _, t := range b.RetainedTopics() {
+		row(t).Retained = true
+	}
+
+	out := make([]ports.TopicStats, 0, len(rows))
+	for _, r := range rows {
+		r.Subscribers = b.Subscribers(r.Topic)
+		out = append(out, *r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Topic < out[j].Topic })
+	return out
+}
+
+// Stats — счётчики доставки и потерь по подпискам (ports.StatsProvider).
+func (b *InMemoryEventBus) Stats() ports.BusStats {
+	b.mu.RLock()
+	subs := make([]ports.SubscriptionStats, 0, len(b.all))
+	for _, sub := range b.all {
+		subs = append(subs, sub.stats())
+	}
+	b.mu.RUnlock()
+	sort.Slice(subs, func(i, j int) bool { return subs[i].ID < subs[j].ID })
+	return ports.BusStats{
+		Published:     b.published.Load(),
+		Delivered:     b.delivered.Load(),
+		Dropped:       b.dropped.Load(),
+		Subscriptions: subs,
+	}
+}
@@ -0,0 +1,153 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"example.com/ffp/platform/ports"
+)
+
+func TestEventBusOverflow(t *testing.T) {
+	tests := []struct {
+		name       string
+		opt        ports.SubscribeOption
+		wantQueued []any
+		delivered  uint64
+	}{
+		{"drop newest", ports.WithOverflow(ports.DropNewest), []any{1, 2}, 2},
+		{"drop oldest", ports.WithOverflow(ports.DropOldest), []any{4, 5}, 5},
+		{"block then drop", ports.WithBlock(5 * time.Millisecond), []any{1, 2}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			b := NewInMemoryEventBus()
+			ch, unsub, err := b.Subscribe(ctx, "t", ports.WithSubscriber("slow"), ports.WithBufferSize(2), tt.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer unsub()
+			for i := 1; i <= 5; i++ {
+				if err := b.Publish(ctx, "t", i); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			st := b.Stats()
+			if len(st.Subscriptions) != 1 {
+				t.Fatalf("subscriptions = %d, want 1", len(st.Subscriptions))
+			}
+			sub := st.Subscriptions[0]
+			if sub.Name != "slow" || sub.Queued != 2 || sub.Delivered != tt.delivered || sub.Dropped != 3 {
+				t.Fatalf("subscription stats = %+v, want queued 2, delivered %d, dropped 3", sub, tt.delivered)
+			}
+			if st.Published != 5 || st.Delivered != tt.delivered || st.Dropped != 3 {
+				t.Fatalf("bus stats = %+v, want published 5, delivered %d, dropped 3", st, tt.delivered)
+			}
+			for _, ts := range b.TopicStats() {
+				if ts.Topic == "t" && (ts.Published != 5 || ts.Dropped != 3) {
+					t.Fatalf("topic stats = %+v, want published 5, dropped 3", ts)
+				}
+			}
+
+			var got []any
+			for len(ch) > 0 {
+				got = append(got, <-ch)
+			}
+			if !reflect.DeepEqual(got, tt.wantQueued) {
+				t.Fatalf("queued = %v, want %v", got, tt.wantQueued)
+			}
+		})
+	}
+}
+
+// Block дожидается места, если подписчик успевает прочитать до таймаута.
+func TestEventBusBlockWaitsForReader(t *testing.T) {
+	ctx := context.Background()
+	b := NewInMemoryEventBus()
+	ch, unsub, err := b.Subscribe(ctx, "t", ports.WithBufferSize(1), ports.WithBlock(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unsub()
+	_ = b.Publish(ctx, "t", 1)
+
+	published := make(chan error, 1)
+	go func() { published <- b.Publish(ctx, "t", 2) }()
+	if v := <-ch; v != 1 {
+		t.Fatalf("first = %v, want 1", v)
+	}
+	if err := <-published; err != nil {
+		t.Fatal(err)
+	}
+	if v := <-ch; v != 2 {
+		t.Fatalf("second = %v, want 2", v)
+	}
+	if st := b.Stats(); st.Dropped != 0 || st.Delivered != 2 {
+		t.Fatalf("stats = %+v, want delivered 2, dropped 0", st)
+	}
+}
+
+// Отмена ctx прерывает ожидание Block: Publish возвращает ошибку ctx, в потери подписки это не идёт.
+func TestEventBusBlockCancelled(t *testing.T) {
+	b := NewInMemoryEventBus()
+	_, unsub, err := b.Subscribe(context.Background(), "t", ports.WithBufferSize(1), ports.WithBlock(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unsub()
+	_ = b.Publish(context.Background(), "t", 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if err := b.Publish(ctx, "t", 2); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Publish = %v, want deadline exceeded", err)
+	}
+	if st := b.Stats(); st.Dropped != 0 || st.Delivered != 1 {
+		t.Fatalf("stats = %+v, want delivered 1, dropped 0", st)
+	}
+}
+
+func TestEventBusInvalidOverflow(t *testing.T) {
+	b := NewInMemoryEventBus()
+	if _, _, err := b.Subscribe(context.Background(), "t", ports.WithOverflow("drop_random")); err == nil {
+		t.Fatal("Subscribe with unknown overflow policy succeeded")
+	}
+}
+
+// Счётчики тем без точных подписок забываются, если в тему не публиковали целое окно.
+func TestTopicCountersEvicted(t *testing.T) {
+	ctx := context.Background()
+	clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+	b := NewInMemoryEventBus(WithBusClock(clock))
+	_, unsubExact, _ := b.Subscribe(ctx, "kept")
+	defer unsubExact()
+	_, unsubWild, _ := b.Subscribe(ctx, "orders.>")
+	defer unsubWild()
+
+	for i := 0; i < 100; i++ {
+		_ = b.Publish(ctx, "orders."+strconv.Itoa(i), i)
+	}
+	_ = b.Publish(ctx, "kept", 1)
+	clock.Advance(30 * time.Second)
+	_ = b.Publish(ctx, "recent", 1)
+	clock.Advance(31 * time.Second)
+
+	var got []string
+	for _, ts := range b.TopicStats() {
+		got = append(got, ts.Topic)
+	}
+	if want := []string{"kept", "recent"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("topics = %v, want %v", got, want)
+	}
+	b.topicMu.Lock()
+	n := len(b.topics)
+	b.topicMu.Unlock()
+	if n != 2 {
+		t.Fatalf("topic counters = %d, want 2", n)
+	}
+}
@@ -2,6 +2,7 @@ package runtime
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"time"
 
@@ -38,13 +39,33 @@ func (KernelModuleBase) Health() contracts.Health {
 	return contracts.Health{Status: contracts.HealthStopped, Since: time.Now()}
 }
 
+// DefaultFSMHistory — сколько последних переходов хранит FSM по умолчанию.
+const DefaultFSMHistory = 32
+
+// Transition — запись истории переходов FSM.
+type Transition struct {
+	From     contracts.LifecycleState `json:"from"`
+	To       contracts.LifecycleState `json:"to"`
+	At       time.Time                `json:"at"`
+	Reason   string                   `json:"reason,omitempty"`
+	Err      string                   `json:"error,omitempty"`
+	Stack    string                   `json:"stack,omitempty"`    // стек, если переход вызван паникой hook-а
+	Rejected bool                     `json:"rejected,omitempty"` // переход запрещён таблицей и не выполнен
+}
+
 // FSM — конечный автомат жизненного цикла ядра.
+// Переходы проверяются по таблице contracts.LifecycleState.CanTransition.
 type FSM struct {
-	mu     sync.RWMutex
-	state  contracts.LifecycleState
-	kernel KernelModule
-	host   KernelHost
-	on     func(from, to contracts.LifecycleState, err error)
+	mu       sync.RWMutex
+	state    contracts.LifecycleState
+	kernel   KernelModule
+	host     KernelHost
+	on       func(from, to contracts.LifecycleState, err error)
+	history  []Transition
+	limit    int
+	timeouts HookTimeouts
+	panic    *PanicError
+	failed   contracts.Health // здоровье на момент перехода в Failed (см. Health)
 }
 
 type FSMOption func(*FSM)
@@ -54,8 +75,17 @@ func WithTransitionHook(h func(from, to contracts.LifecycleState, err error)) FS
 	return func(f *FSM) { f.on = h }
 }
 
+// WithHistoryLimit задаёт размер истории переходов (по умолчанию DefaultFSMHistory).
+func WithHistoryLimit(n int) FSMOption {
+	return func(f *FSM) {
+		if n > 0 {
+			f.limit = n
+		}
+	}
+}
+
 func NewFSM(k KernelModule, h KernelHost, opts ...FSMOption) *FSM {
-	f := &FSM{kernel: k, host: h, state: contracts.StateLoad}
+	f := &FSM{kernel: k, host: h, state: contracts.StateLoad, limit: DefaultFSMHistory}
 	for _, o := range opts {
 		o(f)
 	}
@@ -68,68 +98,165 @@ func (f *FSM) State() contracts.LifecycleState {
 	return f.state
 }
 
-func (f *FSM) set(to contracts.LifecycleState, err error) {
+// History возвращает копию истории переходов (от старых к новым).
+func (f *FSM) History() []Transition {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return append([]Transition(nil), f.history...)
+}
+
+// record дописывает запись в историю; вызывается под f.mu.
+func (f *FSM) record(t Transition) {
+	f.history = append(f.history, t)
+	if over := len(f.history) - f.limit; over > 0 {
+		f.history = append(f.history[:0], f.history[over:]...)
+	}
+}
+
+// check проверяет, что переход в to допустим из текущего состояния.
+// Запрещённая попытка попадает в историю с Rejected.
+func (f *FSM) check(to contracts.LifecycleState) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.state.CanTransition(to) {
+		return nil
+	}
+	err := &contracts.TransitionError{From: f.state, To: to}
+	f.record(Transition{From: f.state, To: to, At: time.Now(), Err: err.Error(), Rejected: true})
+	return err
+}
+
+// set выполняет переход, если он разрешён таблицей; cause — ошибка hook-а, приведшая к переходу.
+func (f *FSM) set(to contracts.LifecycleState, reason string, cause error) error {
 	f.mu.Lock()
 	from := f.state
+	t := Transition{From: from, To: to, At: time.Now(), Reason: reason}
+	if cause != nil {
+		t.Err = cause.Error()
+		var pe *PanicError
+		if errors.As(cause, &pe) {
+			t.Stack = pe.Stack
+		}
+	}
+	if !from.CanTransition(to) {
+		err := &contracts.TransitionError{From: from, To: to}
+		t.Rejected = true
+		if t.Err == "" {
+			t.Err = err.Error()
+		}
+		f.record(t)
+		f.mu.Unlock()
+		return err
+	}
 	f.state = to
+	if to == contracts.StateFailed {
+		f.failed = contracts.Health{Status: contracts.HealthFailed, Reason: reason, Since: t.At}
+		if t.Err != "" {
+			f.failed.Reason = t.Err
+		}
+	}
+	f.record(t)
 	cb := f.on
 	f.mu.Unlock()
 	if cb != nil {
-		cb(from, to, err)
+		cb(from, to, cause)
 	}
+	return nil
+}
+
+// fail переводит FSM в Failed из-за ошибки hook-а и возвращает эту ошибку.
+func (f *FSM) fail(hook string, err error) error {
+	var pe *PanicError
+	if errors.As(err, &pe) {
+		f.notePanic(pe)
+	}
+	_ = f.set(contracts.StateFailed, hook, err)
+	return err
+}
+
+// hook вызывает hook ядра с лимитом времени из HookTimeouts.
+func (f *FSM) hook(ctx context.Context, name string, fn func(context.Context) error) error {
+	return callHook(ctx, name, f.timeouts.For(name), fn)
 }
 
 // Run проходит последовательность Load→Init→Configure→Start→Ready.
-// При ошибке переводит в Failed и возвращает ошибку.
+// При ошибке (в том числе превышении лимита hook-а, см. WithHookTimeouts) переводит в Failed
+// и возвращает ошибку. Допустим только из Load.
 func (f *FSM) Run(ctx context.Context, cfg map[string]any) error {
-	if err := f.kernel.OnLoad(ctx, f.host); err != nil {
-		f.set(contracts.StateFailed, err)
+	if err := f.check(contracts.StateInit); err != nil {
 		return err
 	}
-	f.set(contracts.StateInit, nil)
-
-	if err := f.kernel.OnInit(ctx); err != nil {
-		f.set(contracts.StateFailed, err)
+	if err := f.hook(ctx, "OnLoad", func(ctx context.Context) error { return f.kernel.OnLoad(ctx, f.host) }); err != nil {
+		return f.fail("OnLoad", err)
+	}
+	if err := f.set(contracts.StateInit, "", nil); err != nil {
 		return err
 	}
-	f.set(contracts.StateConfigure, nil)
 
-	if err := f.kernel.OnConfigure(ctx, cfg); err != nil {
-		f.set(contracts.StateFailed, err)
+	if err := f.hook(ctx, "OnInit", f.kernel.OnInit); err != nil {
+		return f.fail("OnInit", err)
+	}
+	if err := f.set(contracts.StateConfigure, "", nil); err != nil {
 		return err
 	}
-	f.set(contracts.StateStart, nil)
 
-	if err := f.kernel.OnStart(ctx); err != nil {
-		f.set(contracts.StateFailed, err)
+	if err := f.hook(ctx, "OnConfigure", func(ctx context.Context) error { return f.kernel.OnConfigure(ctx, cfg) }); err != nil {
+		return f.fail("OnConfigure", err)
+	}
+	if err := f.set(contracts.StateStart, "", nil); err != nil {
 		return err
 	}
 
+	if err := f.hook(ctx, "OnStart", f.kernel.OnStart); err != nil {
+		return f.fail("OnStart", err)
+	}
+
 	// Успешный старт — ядро готово.
-	f.set(contracts.StateReady, nil)
+	if err := f.set(contracts.StateReady, "", nil); err != nil {
+		return err
+	}
 
-	// Доп. хук "OnReady" — необязательный этап.
-	_ = f.kernel.OnReady(ctx)
+	// Доп. хук "OnReady" — необязательный этап, но ошибка, паника или превышение лимита роняют ядро в Failed.
+	if err := f.hook(ctx, "OnReady", f.kernel.OnReady); err != nil {
+		return f.fail("OnReady", err)
+	}
 
 	return nil
 }
 
-// Drain переводит ядро в Draining, вызывает OnDrain.
+// Degrade переводит готовое ядро в Degraded (например, по health-сигналу).
+func (f *FSM) Degrade(reason string) error {
+	return f.set(contracts.StateDegraded, reason, nil)
+}
+
+// Recover возвращает ядро из Degraded в Ready.
+func (f *FSM) Recover(reason string) error {
+	return f.set(contracts.StateReady, reason, nil)
+}
+
+// Fail переводит ядро в Failed по сигналу вне hook-ов (например, супервизор ядра сдался).
+func (f *FSM) Fail(reason string) error {
+	return f.set(contracts.StateFailed, reason, errors.New(reason))
+}
+
+// Drain переводит ядро в Draining, вызывает OnDrain. Допустим из Ready и Degraded.
 func (f *FSM) Drain(ctx context.Context) error {
-	f.set(contracts.StateDraining, nil)
-	if err := f.kernel.OnDrain(ctx); err != nil {
-		f.set(contracts.StateFailed, err)
+	if err := f.set(contracts.StateDraining, "", nil); err != nil {
 		return err
 	}
+	if err := f.hook(ctx, "OnDrain", f.kernel.OnDrain); err != nil {
+		return f.fail("OnDrain", err)
+	}
 	return nil
 }
 
 // Stop переводит в Stopped, вызывает OnStop.
 func (f *FSM) Stop(ctx context.Context) error {
-	if err := f.kernel.OnStop(ctx); err != nil {
-		f.set(contracts.StateFailed, err)
+	if err := f.check(contracts.StateStopped); err != nil {
 		return err
 	}
-	f.set(contracts.StateStopped, nil)
-	return nil
+	if err := f.hook(ctx, "OnStop", f.kernel.OnStop); err != nil {
+		return f.fail("OnStop", err)
+	}
+	return f.set(contracts.StateStopped, "", nil)
 }
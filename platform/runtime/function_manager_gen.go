@@ -0,0 +1,245 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"example.com/ffp/platform/contracts"
+	"example.com/ffp/platform/ports"
+)
+
+// FunctionFactory создаёт функциональное ядро (FunctionScope) по его id.
+type FunctionFactory func(id string) KernelModule
+
+var (
+	functionFactoriesMu sync.RWMutex
+	functionFactories   = map[string]FunctionFactory{}
+)
+
+// RegisterFunctionFactory регистрирует фабрику функционального ядра по kind.
+func RegisterFunctionFactory(kind string, f FunctionFactory) {
+	if kind == "" || f == nil {
+		return
+	}
+	functionFactoriesMu.Lock()
+	functionFactories[kind] = f
+	functionFactoriesMu.Unlock()
+}
+
+func functionFactory(kind string) (FunctionFactory, bool) {
+	functionFactoriesMu.RLock()
+	defer functionFactoriesMu.RUnlock()
+	f, ok := functionFactories[kind]
+	return f, ok
+}
+
+// FunctionReporter получает сведения о функциональных ядрах (в rk — DiscoveryRegistry Root-ядра).
+type FunctionReporter interface {
+	ReportFunction(parent string, m contracts.Manifest, h contracts.Health)
+	RemoveFunction(id string)
+}
+
+// FunctionSpec — описание функционального ядра внутри домена.
+type FunctionSpec struct {
+	ID     string
+	Kind   string
+	Config map[string]any
+	Hooks  HookTimeouts
+}
+
+// FunctionStatus — снимок состояния функционального ядра.
+type FunctionStatus struct {
+	ID     string                   `json:"id"`
+	Kind   string                   `json:"kind"`
+	State  contracts.LifecycleState `json:"state"`
+	Health contracts.Health         `json:"health"`
+}
+
+// FunctionKernelID — id функционального ядра в дереве Root→Domain→Function: "<parent>/<id>".
+func FunctionKernelID(parent, id string) string { return parent + "/" + id }
+
+type functionRun struct {
+	spec   FunctionSpec
+	id     string // полный id (FunctionKernelID)
+	fsm    *FSM
+	cancel context.CancelFunc
+}
+
+// FunctionManager загружает функциональные ядра внутри доменного ядра: у каждой функции
+// свой FSM и KernelHost (scope=function, parent=id домена).
+type FunctionManager struct {
+	parent   KernelHost
+	reporter FunctionReporter
+
+	mu      sync.Mutex
+	funcs   map[string]*functionRun // в funcs попадает только готовый run (fsm и cancel заданы)
+	loading map[string]struct{}     // id, для которых Load собирает ядро
+}
+
+type FMOption func(*FunctionManager)
+
+// WithReporter задаёт репортёр явно (по умолчанию берётся у хоста домена, см. WithFunctionReporter).
+func WithReporter(r FunctionReporter) FMOption {
+	return func(m *FunctionManager) { m.reporter = r }
+}
+
+// NewFunctionManager создаёт менеджер функций для доменного ядра с хостом parent.
+func NewFunctionManager(parent KernelHost, opts ...FMOption) *FunctionManager {
+	m := &FunctionManager{parent: parent, funcs: make(map[string]*functionRun), loading: make(map[string]struct{})}
+	if h, ok := parent.(interface{ FunctionReporter() FunctionReporter }); ok {
+		m.reporter = h.FunctionReporter()
+	}
+	for _, o := range opts {
+		o(m)
+	}
+	return m
+}
+
+// Load создаёт функциональное ядро через фабрику kind и проводит его по жизненному циклу до Ready.
+func (m *FunctionManager) Load(ctx context.Context, spec FunctionSpec) error {
+	if spec.ID == "" {
+		return errors.New("function id is required")
+	}
+	f, ok := functionFactory(spec.Kind)
+	if !ok {
+		return fmt.Errorf("function %s: unknown kind %q", spec.ID, spec.Kind)
+	}
+	m.mu.Lock()
+	_, exists := m.funcs[spec.ID]
+	if _, busy := m.loading[spec.ID]; exists || busy {
+		m.mu.Unlock()
+		return fmt.Errorf("function %s: already loaded", spec.ID)
+	}
+	m.loading[spec.ID] = struct{}{}
+	m.mu.Unlock()
+	run := &functionRun{spec: spec, id: FunctionKernelID(m.parent.ID(), spec.ID)}
+
+	p := m.parent
+	k := f(run.id)
+	bus := p.EventBus()
+	if pv, ok := bus.(*BusView); ok {
+		// у функции свой контракт; строгость — как у домена
+		bus = pv.Guard().View(run.id, DeclaredTopics(k), pv.Strictness())
+	}
+	host := NewHost(run.id, contracts.FunctionScope,
+		WithParent(p.ID()),
+		WithLogger(ports.NewTeeLogger(UnguardedBus(p.EventBus()), run.id, string(contracts.FunctionScope), spec.Kind)),
+		WithEventBus(bus),
+		WithRPC(p.RPC()),
+		WithStream(p.Stream()),
+		WithConfig(spec.Config),
+		WithFunctionReporter(m.reporter),
+	)
+	run.fsm = NewFSM(k, host,
+		WithHookTimeouts(spec.Hooks),
+		WithTransitionHook(func(from, to contracts.LifecycleState, err error) { m.report(run, to, err) }),
+	)
+
+	fctx, cancel := context.WithCancel(ctx)
+	run.cancel = cancel
+
+	m.mu.Lock()
+	delete(m.loading, spec.ID)
+	m.funcs[spec.ID] = run
+	m.mu.Unlock()
+
+	if err := run.fsm.Run(fctx, spec.Config); err != nil {
+		// как Unload: функция не остаётся загруженной, её можно загрузить снова
+		m.mu.Lock()
+		if m.funcs[spec.ID] == run {
+			delete(m.funcs, spec.ID)
+		}
+		m.mu.Unlock()
+		cancel()
+		if m.reporter != nil {
+			m.reporter.RemoveFunction(run.id)
+		}
+		return fmt.Errorf("function %s: %w", spec.ID, err)
+	}
+	return nil
+}
+
+// report переносит смену состояния функции в репортёр.
+func (m *FunctionManager) report(run *functionRun, to contracts.LifecycleState, err error) {
+	if m.reporter == nil {
+		return
+	}
+	var h contracts.Health
+	switch to {
+	case contracts.StateLoad, contracts.StateInit:
+		return // запись появляется, когда ядро начало конфигурироваться
+	case contracts.StateStopped:
+		m.reporter.RemoveFunction(run.id)
+		return
+	case contracts.StateFailed:
+		h = contracts.Health{Status: contracts.HealthFailed, Since: time.Now()}
+		if err != nil {
+			h.Reason = err.Error()
+		}
+	case contracts.StateReady, contracts.StateDegraded:
+		h = run.fsm.Health()
+	case contracts.StateDraining:
+		h = contracts.Health{Status: contracts.HealthDraining, Since: time.Now()}
+	default:
+		h = contracts.Health{Status: contracts.HealthStopped, Reason: "starting: " + string(to), Since: time.Now()}
+	}
+	mf := run.fsm.kernel.Manifest()
+	mf.KernelID, mf.Scope = run.id, contracts.FunctionScope
+	m.reporter.ReportFunction(m.parent.ID(), mf, h)
+}
+
+// Unload проводит функцию через Drain/Stop и удаляет её.
+func (m *FunctionManager) Unload(ctx context.Context, id string) error {
+	m.mu.Lock()
+	run, ok := m.funcs[id]
+	delete(m.funcs, id)
+	m.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("function %s: not loaded", id)
+	}
+	var errs []error
+	if run.fsm.State() == contracts.StateReady || run.fsm.State() == contracts.StateDegraded {
+		errs = append(errs, run.fsm.Drain(ctx))
+	}
+	errs = append(errs, run.fsm.Stop(ctx))
+	run.cancel()
+	if m.reporter != nil {
+		m.reporter.RemoveFunction(run.id)
+	}
+	return errors.Join(errs...)
+}
+
+// StopAll выгружает все функции (обычно из OnStop домена).
+func (m *FunctionManager) StopAll(ctx context.Context) error {
+	m.mu.Lock()
+	ids := make([]string, 0, len(m.funcs))
+	for id := range m.funcs {
+		ids = append(ids, id)
+	}
+	m.mu.Unlock()
+	var errs []error
+	for _, id := range ids {
+		errs = append(errs, m.Unload(ctx, id))
+	}
+	return errors.Join(errs...)
+}
+
+// List возвращает состояние загруженных функций.
+func (m *FunctionManager) List() []FunctionStatus {
+	m.mu.Lock()
+	runs := make([]*functionRun, 0, len(m.funcs))
+	for _, r := range m.funcs {
+		runs = append(runs, r)
+	}
+	m.mu.Unlock()
+	out := make([]FunctionStatus, 0, len(runs))
+	for _, r := range runs {
+		out = append(out, FunctionStatus{ID: r.id, Kind: r.spec.Kind, State: r.fsm.State(), Health: r.fsm.Health()})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
@@ -0,0 +1,87 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultHookTimeout — лимит hook-а жизненного цикла, если он не задан явно.
+const DefaultHookTimeout = 10 * time.Second
+
+// HookTimeouts — лимиты времени на hook-и жизненного цикла.
+// 0 — DefaultHookTimeout, отрицательное значение — без лимита.
+type HookTimeouts struct {
+	Load      time.Duration
+	Init      time.Duration
+	Configure time.Duration
+	Start     time.Duration
+	Ready     time.Duration
+	Drain     time.Duration
+	Stop      time.Duration
+}
+
+// For возвращает лимит для hook-а по его имени (OnLoad, OnStart, ...).
+func (t HookTimeouts) For(hook string) time.Duration {
+	var d time.Duration
+	switch hook {
+	case "OnLoad":
+		d = t.Load
+	case "OnInit":
+		d = t.Init
+	case "OnConfigure":
+		d = t.Configure
+	case "OnStart":
+		d = t.Start
+	case "OnReady":
+		d = t.Ready
+	case "OnDrain":
+		d = t.Drain
+	case "OnStop":
+		d = t.Stop
+	}
+	if d == 0 {
+		d = DefaultHookTimeout
+	}
+	return d
+}
+
+// HookTimeoutError — hook не уложился в отведённое время.
+type HookTimeoutError struct {
+	Hook    string
+	Timeout time.Duration
+}
+
+func (e *HookTimeoutError) Error() string {
+	return fmt.Sprintf("hook %s exceeded %s", e.Hook, e.Timeout)
+}
+
+// WithHookTimeouts задаёт лимиты hook-ов FSM.
+func WithHookTimeouts(t HookTimeouts) FSMOption {
+	return func(f *FSM) { f.timeouts = t }
+}
+
+// callHook вызывает hook с лимитом d. По истечении лимита ctx hook-а отменяется,
+// а FSM получает *HookTimeoutError, не дожидаясь возврата hook-а.
+// ctx hook-а живёт только до его возврата: фоновую работу ядро ведёт на своём контексте.
+// Паника в hook-е возвращается как *PanicError.
+func callHook(ctx context.Context, hook string, d time.Duration, fn func(context.Context) error) error {
+	if d < 0 {
+		return safeCall(ctx, hook, fn)
+	}
+	hctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	timer := time.AfterFunc(d, cancel)
+	done := make(chan error, 1)
+	go func() { done <- safeCall(hctx, hook, fn) }()
+	select {
+	case err := <-done:
+		timer.Stop()
+		return err
+	case <-hctx.Done():
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return &HookTimeoutError{Hook: hook, Timeout: d}
+	}
+}
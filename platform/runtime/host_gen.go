@@ -2,6 +2,7 @@ package runtime
 
 import (
 	"context"
+	"sync"
 
 	"example.com/ffp/platform/contracts"
 	"example.com/ffp/platform/ports"
@@ -11,21 +12,33 @@ import (
 type KernelHost interface {
 	ID() string
 	Scope() contracts.Scope
+	// Parent — id ядра-родителя ("" у Root и доменов, запущенных Root-ядром).
+	Parent() string
 	Logger() ports.Logger
 	RPC() ports.RPC
 	EventBus() ports.EventBus
 	Stream() ports.Stream
 	Config() map[string]any
+	// ExposeSupervisor открывает супервизор ядра для интроспекции (admin API rk) под именем name.
+	ExposeSupervisor(name string, s *Supervisor)
+	// Supervisors — открытые супервизоры ядра.
+	Supervisors() map[string]*Supervisor
 }
 
 type host struct {
 	id     string
 	scope  contracts.Scope
+	parent string
 	logger ports.Logger
 	rpc    ports.RPC
 	bus    ports.EventBus
 	stream ports.Stream
 	cfg    map[string]any
+
+	functions FunctionReporter
+
+	mu   sync.Mutex
+	sups map[string]*Supervisor
 }
 
 // NewHost создаёт KernelHost. Все поля опциональны, но Logger по умолчанию — noop.
@@ -51,6 +64,7 @@ func WithLogger(l ports.Logger) HostOption {
 		}
 	}
 }
+func WithParent(id string) HostOption          { return func(h *host) { h.parent = id } }
 func WithRPC(r ports.RPC) HostOption           { return func(h *host) { h.rpc = r } }
 func WithEventBus(b ports.EventBus) HostOption { return func(h *host) { h.bus = b } }
 func WithStream(s ports.Stream) HostOption     { return func(h *host) { h.stream = s } }
@@ -67,12 +81,44 @@ func WithConfig(cfg map[string]any) HostOption {
 
 func (h *host) ID() string               { return h.id }
 func (h *host) Scope() contracts.Scope   { return h.scope }
+func (h *host) Parent() string           { return h.parent }
 func (h *host) Logger() ports.Logger     { return h.logger }
 func (h *host) RPC() ports.RPC           { return h.rpc }
 func (h *host) EventBus() ports.EventBus { return h.bus }
 func (h *host) Stream() ports.Stream     { return h.stream }
 func (h *host) Config() map[string]any   { return h.cfg }
 
+// WithFunctionReporter задаёт, куда FunctionManager ядра сообщает о функциональных ядрах.
+func WithFunctionReporter(r FunctionReporter) HostOption {
+	return func(h *host) { h.functions = r }
+}
+
+// FunctionReporter возвращает репортёр функций хоста (nil — не задан).
+func (h *host) FunctionReporter() FunctionReporter { return h.functions }
+
+func (h *host) ExposeSupervisor(name string, s *Supervisor) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.sups == nil {
+		h.sups = make(map[string]*Supervisor)
+	}
+	if s == nil {
+		delete(h.sups, name)
+		return
+	}
+	h.sups[name] = s
+}
+
+func (h *host) Supervisors() map[string]*Supervisor {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make(map[string]*Supervisor, len(h.sups))
+	for k, v := range h.sups {
+		out[k] = v
+	}
+	return out
+}
+
 // noopLogger — безопасная заглушка.
 type noopLogger struct{}
 
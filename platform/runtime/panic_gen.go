@@ -0,0 +1,78 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"example.com/ffp/platform/contracts"
+)
+
+// PanicError — паника в hook-е ядра, превращённая в ошибку (со стеком).
+type PanicError struct {
+	Hook  string    `json:"hook"`
+	Value string    `json:"value"`
+	Stack string    `json:"stack"`
+	At    time.Time `json:"at"`
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("hook %s panicked: %s", e.Hook, e.Value)
+}
+
+// safeCall вызывает fn, превращая панику в *PanicError.
+// Ловит только панику в самом hook-е: горутины, запущенные ядром, остаются на его совести.
+func safeCall(ctx context.Context, hook string, fn func(context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Hook: hook, Value: fmt.Sprint(r), Stack: string(debug.Stack()), At: time.Now()}
+		}
+	}()
+	return fn(ctx)
+}
+
+// Health возвращает kernel.Health(); паника в нём переводит ядро в Failed.
+// В Failed ядро не опрашивается: отдаётся здоровье, сохранённое при переходе.
+func (f *FSM) Health() contracts.Health {
+	f.mu.RLock()
+	if f.state == contracts.StateFailed {
+		h := f.failed
+		f.mu.RUnlock()
+		return h
+	}
+	f.mu.RUnlock()
+	var h contracts.Health
+	err := safeCall(context.Background(), "Health", func(context.Context) error {
+		h = f.kernel.Health()
+		return nil
+	})
+	if err != nil {
+		_ = f.fail("Health", err)
+		return contracts.Health{Status: contracts.HealthFailed, Reason: err.Error(), Since: time.Now()}
+	}
+	return h
+}
+
+// LastPanic возвращает последнюю пойманную панику hook-а (nil — паник не было).
+func (f *FSM) LastPanic() *PanicError {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.panic
+}
+
+// notePanic сохраняет панику и пишет её в лог ядра.
+func (f *FSM) notePanic(p *PanicError) {
+	f.mu.Lock()
+	f.panic = p
+	f.mu.Unlock()
+	if f.host == nil || f.host.Logger() == nil {
+		return
+	}
+	f.host.Logger().Log(context.Background(), "ERROR", "kernel hook panicked", map[string]any{
+		"kernel_id": f.host.ID(),
+		"hook":      p.Hook,
+		"panic":     p.Value,
+		"stack":     p.Stack,
+	})
+}
@@ -0,0 +1,87 @@
+package runtime
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"example.com/ffp/platform/contracts"
+)
+
+// WithControlPipe передаёт дочернему процессу управляющий пайп (fd 3, номер — в RK_CONTROL_FD).
+// Кадры contracts.ControlMessage пишутся в него построчно, без префикса.
+func WithControlPipe() PROption { return func(p *ProcessRunner) { p.controlPipe = true } }
+
+// WithHandshakeRequired — готовность наступает только после кадра hello (READY и HTTP-проба игнорируются).
+func WithHandshakeRequired() PROption { return func(p *ProcessRunner) { p.handshakeRequired = true } }
+
+// Handshake возвращает первый принятый кадр hello.
+func (p *ProcessRunner) Handshake() (contracts.ControlMessage, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.hello == nil {
+		return contracts.ControlMessage{}, false
+	}
+	return *p.hello, true
+}
+
+// Control возвращает канал кадров, пришедших после первого hello (health, повторные hello).
+// Канал закрывается при выходе процесса. Чтение stdout он не тормозит: при полном буфере
+// отбрасывается самый старый кадр, свежее состояние (последний health) не теряется (ControlDropped).
+func (p *ProcessRunner) Control() <-chan contracts.ControlMessage { return p.ctrlCh }
+
+// ControlDropped — сколько кадров отброшено из-за переполненного буфера Control().
+func (p *ProcessRunner) ControlDropped() uint64 { return p.ctrlDropped.Load() }
+
+func (p *ProcessRunner) readControl(r io.ReadCloser) {
+	defer r.Close()
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		if line := sc.Text(); line != "" {
+			p.handleControl(line)
+		}
+	}
+}
+
+// handleControl разбирает кадр; первый hello фиксируется как handshake и означает готовность.
+// Содержимое кадров здесь не валидируется — это решение Root-ядра.
+func (p *ProcessRunner) handleControl(line string) {
+	msg, err := contracts.ParseControlFrame(line)
+	if err != nil {
+		p.emit(ProcEvent{Time: p.clock.Now(), Type: ProcControlError, Err: err})
+		return
+	}
+	if msg.Type == contracts.ControlHello {
+		p.mu.Lock()
+		first := p.hello == nil
+		if first {
+			m := msg
+			p.hello = &m
+		}
+		p.mu.Unlock()
+		if first {
+			p.ready.Store(true)
+			p.emit(ProcEvent{Time: p.clock.Now(), Type: ProcHandshake, Note: string(msg.Type)})
+			p.emit(ProcEvent{Time: p.clock.Now(), Type: ProcReady, Note: "handshake"})
+			return
+		}
+	}
+	// пишет в ctrlCh только эта горутина, поэтому освобождённое место никто не займёт
+	for {
+		select {
+		case p.ctrlCh <- msg:
+			return
+		default:
+		}
+		select {
+		case old := <-p.ctrlCh:
+			// первая потеря — событием, дальше только счётчик
+			if p.ctrlDropped.Add(1) == 1 {
+				err := fmt.Errorf("control buffer full (%d): oldest %s frame dropped", cap(p.ctrlCh), old.Type)
+				p.emit(ProcEvent{Time: p.clock.Now(), Type: ProcControlError, Err: err})
+			}
+		default:
+		}
+	}
+}
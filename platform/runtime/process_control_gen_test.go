@@ -0,0 +1,42 @@
+package runtime
+
+import (
+	"strconv"
+	"testing"
+
+	"example.com/ffp/platform/contracts"
+)
+
+// При полном буфере Control() теряются самые старые кадры, последний health доходит.
+func TestControlDropsOldest(t *testing.T) {
+	var errs []ProcEvent
+	p := NewProcessRunner("unused", nil, WithProcEventHook(func(e ProcEvent) {
+		if e.Type == ProcControlError {
+			errs = append(errs, e)
+		}
+	}))
+	n := cap(p.ctrlCh) + 3
+	for i := 0; i < n; i++ {
+		b, err := contracts.EncodeControlFrame(contracts.ControlMessage{
+			Type:   contracts.ControlHealth,
+			Health: &contracts.Health{Status: contracts.HealthReady, Reason: strconv.Itoa(i)},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.handleControl(string(b))
+	}
+
+	if got := p.ControlDropped(); got != 3 {
+		t.Fatalf("ControlDropped = %d, want 3", got)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("control_error events = %d, want 1 for the first drop", len(errs))
+	}
+	for want := 3; want < n; want++ {
+		msg := <-p.Control()
+		if msg.Health == nil || msg.Health.Reason != strconv.Itoa(want) {
+			t.Fatalf("frame = %+v, want health %d", msg, want)
+		}
+	}
+}
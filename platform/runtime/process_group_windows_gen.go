@@ -0,0 +1,20 @@
+//go:build windows
+
+package runtime
+
+import (
+	"os"
+	"os/exec"
+)
+
+// На Windows групп процессов в POSIX-смысле нет, а мягкого сигнала — тоже:
+// и Stop, и Kill завершают процесс принудительно.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+func terminateGroup(proc *os.Process) error { return proc.Kill() }
+
+func killGroup(proc *os.Process) error { return proc.Kill() }
+
+func groupAlive(pgid int) bool { return false }
+
+func exitSignal(ps *os.ProcessState) string { return "" }
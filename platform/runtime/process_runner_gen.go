@@ -4,16 +4,18 @@ import (
 	"bufio"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"runtime"
 	"strings"
 	"sync"
-	"syscall"
+	"sync/atomic"
 	"time"
+
+	"example.com/ffp/platform/contracts"
 )
 
 // LogLine — единичная строка лога процесса.
@@ -32,6 +34,15 @@ const (
 	ProcReady   ProcEventType = "ready"
 	ProcProbeOK ProcEventType = "probe_ok"
 	ProcProbeKO ProcEventType = "probe_ko"
+	// handshake-протокол (см. contracts.ControlMessage)
+	ProcHandshake    ProcEventType = "handshake"
+	ProcControlError ProcEventType = "control_error"
+	// остановка (см. Shutdown): Note — имя сигнала или причина эскалации
+	ProcSignal       ProcEventType = "signal"
+	ProcGraceExpired ProcEventType = "grace_expired"
+	// лимиты ресурсов (см. WithResourceLimits): как применены и какой превышен
+	ProcLimits        ProcEventType = "limits"
+	ProcLimitExceeded ProcEventType = "limit_exceeded"
 )
 
 // ProcEvent — событие жизненного цикла процесса.
@@ -40,7 +51,7 @@ type ProcEvent struct {
 	Type     ProcEventType
 	ExitCode int
 	Err      error
-	Note     string
+	Note     string // для ProcExit — сигнал, которым завершён процесс (если был)
 }
 
 // ProcessRunner — каркас для запуска внешнего DK-процесса.
@@ -54,67 +65,31 @@ type ProcessRunner struct {
 
 	logCh   chan LogLine
 	onEvent func(ProcEvent)
+	probe   Backoff // паузы между проверками готовности в WaitReady
+	clock   Clock
 
-	mu  sync.Mutex
-	cmd *exec.Cmd
+	// управляющий канал
+	ctrlCh            chan contracts.ControlMessage
+	ctrlDropped       atomic.Uint64 // кадры, не влезшие в ctrlCh
+	controlPipe       bool
+	handshakeRequired bool
+	hello             *contracts.ControlMessage
 
-	ready bool
-}
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	limits ResourceLimits
+	cgroup *cgroup
 
-// BackoffPolicy — параметры экспоненциального backoff с джиттером.
-type BackoffPolicy struct {
-	Min    time.Duration
-	Max    time.Duration
-	Factor float64
-	Jitter float64
-}
-
-func (b BackoffPolicy) withDefaults() BackoffPolicy {
-	out := b
-	if out.Min <= 0 {
-		out.Min = 100 * time.Millisecond
-	}
-	if out.Max <= 0 {
-		out.Max = 30 * time.Second
-	}
-	if out.Factor <= 0 {
-		out.Factor = 2.0
-	}
-	if out.Jitter < 0 {
-		out.Jitter = 0
-	}
-	if out.Jitter > 1 {
-		out.Jitter = 1
-	}
-	return out
-}
+	cgroupParent string           // делегированная cgroup v2 для групп процессов
+	enforcement  LimitEnforcement // как применены limits
 
-func (b BackoffPolicy) duration(attempt int) time.Duration {
-	b = b.withDefaults()
-	// exp := Min * Factor^(attempt-1)
-	exp := float64(b.Min)
-	for i := 1; i < attempt; i++ {
-		exp *= b.Factor
-	}
-	d := time.Duration(exp)
-	if d > b.Max {
-		d = b.Max
-	}
-	// лёгкий джиттер
-	if b.Jitter > 0 {
-		// используем текущее время как источник случайности
-		n := time.Now().UnixNano()
-		j := float64(int64(n%2000)-1000) / 1000.0 // [-1; +1] приблизительно
-		delta := time.Duration(float64(d) * j * b.Jitter)
-		d += delta
-		if d < b.Min {
-			d = b.Min
-		}
-		if d > b.Max {
-			d = b.Max
-		}
-	}
-	return d
+	ready      atomic.Bool
+	done       chan struct{}
+	exitCode   int
+	exitErr    error
+	exitSignal string
+	exitLimit  string      // превышенный лимит ресурсов, если процесс убит за него
+	escalated  atomic.Bool // Shutdown дошёл до SIGKILL
 }
 
 // NewProcessRunner создаёт новый раннер.
@@ -124,6 +99,10 @@ func NewProcessRunner(cmdPath string, args []string, opts ...PROption) *ProcessR
 		args:        append([]string(nil), args...),
 		readyPrefix: "READY",
 		logCh:       make(chan LogLine, 256),
+		ctrlCh:      make(chan contracts.ControlMessage, 16),
+		done:        make(chan struct{}),
+		probe:       ConstantBackoff{Delay: 200 * time.Millisecond},
+		clock:       SystemClock,
 	}
 	for _, o := range opts {
 		o(pr)
@@ -152,7 +131,24 @@ func WithReadyPrefix(prefix string) PROption {
 		}
 	}
 }
-func WithOnEvent(h func(ProcEvent)) PROption { return func(p *ProcessRunner) { p.onEvent = h } }
+func WithProcEventHook(h func(ProcEvent)) PROption {
+	return func(p *ProcessRunner) { p.onEvent = h }
+}
+
+// WithProbeBackoff задаёт паузы между проверками готовности в WaitReady (по умолчанию постоянные 200ms).
+func WithProbeBackoff(b Backoff) PROption {
+	return func(p *ProcessRunner) {
+		if b != nil {
+			p.probe = b
+		}
+	}
+}
+
+// WithProcClock задаёт часы для WaitReady (пробы и таймаут), дочитывания пайпов после выхода
+// и ожиданий в Shutdown (grace, опрос группы, SIGKILL); по умолчанию SystemClock.
+func WithProcClock(c Clock) PROption {
+	return func(p *ProcessRunner) { p.clock = orSystemClock(c) }
+}
 
 // Logs возвращает канал строк логов (stdout/stderr).
 func (p *ProcessRunner) Logs() <-chan LogLine { return p.logCh }
@@ -164,6 +160,10 @@ func (p *ProcessRunner) emit(e ProcEvent) {
 	}
 }
 
+// pipeDrainTimeout — сколько после выхода процесса дочитываются stdout/stderr и управляющий пайп.
+// Потомок, унаследовавший их, держит пайпы открытыми; ждать его Done() не должен.
+const pipeDrainTimeout = 2 * time.Second
+
 // Start запускает процесс и читает stdout/stderr в фоне.
 func (p *ProcessRunner) Start(ctx context.Context) error {
 	p.mu.Lock()
@@ -181,28 +181,89 @@ func (p *ProcessRunner) Start(ctx context.Context) error {
 	if len(p.env) > 0 {
 		cmd.Env = append(os.Environ(), p.env...)
 	}
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
+	// своя группа процессов: остановка и отмена ctx задевают и потомков
+	setProcessGroup(cmd)
+	cmd.Cancel = func() error { return killGroup(cmd.Process) }
+
+	// пайпы свои, а не StdoutPipe: cmd.Wait их не закрывает, и выход процесса
+	// не ждёт потомков, которые их унаследовали (см. pipeDrainTimeout)
+	var pipes, childEnds []*os.File // читающие концы у нас, пишущие — у дочернего процесса
+	closeAll := func() {
+		for _, f := range pipes {
+			f.Close()
+		}
+		for _, f := range childEnds {
+			f.Close()
+		}
+	}
+	pipe := func() (*os.File, error) {
+		r, w, err := os.Pipe()
+		if err != nil {
+			return nil, err
+		}
+		pipes, childEnds = append(pipes, r), append(childEnds, w)
+		return w, nil
+	}
+	var err error
+	if cmd.Stdout, err = pipe(); err != nil {
+		closeAll()
 		return err
 	}
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
+	if cmd.Stderr, err = pipe(); err != nil {
+		closeAll()
 		return err
 	}
+	if p.controlPipe {
+		ctrlW, err := pipe()
+		if err != nil {
+			closeAll()
+			return err
+		}
+		// ExtraFiles[0] становится fd 3 у дочернего процесса
+		cmd.ExtraFiles = []*os.File{ctrlW}
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+		cmd.Env = append(cmd.Env, contracts.EnvControlFD+"=3")
+	}
 
-	if err := cmd.Start(); err != nil {
+	var limits ProcEvent
+	if p.limits.IsZero() {
+		err = cmd.Start()
+	} else {
+		limits, err = p.startLimitedLocked(cmd)
+	}
+	if err != nil {
+		closeAll()
 		return err
 	}
+	for _, w := range childEnds {
+		w.Close() // пишущие концы остаются только у дочернего процесса
+	}
 	p.cmd = cmd
-	p.emit(ProcEvent{Time: time.Now(), Type: ProcStart, Note: filepath.Base(p.cmdPath)})
+	p.emit(ProcEvent{Time: p.clock.Now(), Type: ProcStart, Note: filepath.Base(p.cmdPath)})
+	if limits.Type != "" {
+		p.emit(limits) // ошибка лимитов не мешает запуску: она уходит событием
+	}
 
-	// читалки stdout/stderr
-	go p.readPipe("stdout", stdout)
-	go p.readPipe("stderr", stderr)
+	// читалки stdout/stderr и управляющего пайпа
+	var readers sync.WaitGroup
+	readers.Add(len(pipes))
+	go func() { defer readers.Done(); p.readPipe("stdout", pipes[0]) }()
+	go func() { defer readers.Done(); p.readPipe("stderr", pipes[1]) }()
+	if p.controlPipe {
+		go func() { defer readers.Done(); p.readControl(pipes[2]) }()
+	}
+	drained := make(chan struct{})
+	go func() {
+		readers.Wait()
+		close(drained)
+	}()
 
-	// наблюдаем за завершением
+	// наблюдаем за завершением: сначала сам процесс, затем дочитываем пайпы
 	go func() {
 		err := cmd.Wait()
+		p.drainPipes(drained, pipes)
 		code := 0
 		if err != nil {
 			if ee, ok := err.(*exec.ExitError); ok && ee.ProcessState != nil {
@@ -211,13 +272,42 @@ func (p *ProcessRunner) Start(ctx context.Context) error {
 				code = -1
 			}
 		}
-		p.emit(ProcEvent{Time: time.Now(), Type: ProcExit, ExitCode: code, Err: err})
+		sig := exitSignal(cmd.ProcessState)
+		limit := p.limitExceeded(cmd.ProcessState, sig)
+		p.mu.Lock()
+		p.exitCode, p.exitErr, p.exitSignal, p.exitLimit = code, err, sig, limit
+		if p.cgroup != nil {
+			p.cgroup.remove()
+		}
+		p.mu.Unlock()
+		if limit != "" {
+			p.emit(ProcEvent{Time: p.clock.Now(), Type: ProcLimitExceeded, ExitCode: code, Note: limit})
+		}
+		p.emit(ProcEvent{Time: p.clock.Now(), Type: ProcExit, ExitCode: code, Err: err, Note: sig})
 		close(p.logCh)
+		close(p.ctrlCh)
+		close(p.done)
 	}()
 
 	return nil
 }
 
+// drainPipes ждёт, пока читалки дочитают пайпы, но не дольше pipeDrainTimeout:
+// дальше пайпы, которые держит потомок, закрываются, и недочитанное теряется.
+func (p *ProcessRunner) drainPipes(drained <-chan struct{}, pipes []*os.File) {
+	t := p.clock.NewTimer(pipeDrainTimeout)
+	defer t.Stop()
+	select {
+	case <-drained:
+		return
+	case <-t.C():
+	}
+	for _, f := range pipes {
+		f.Close()
+	}
+	<-drained
+}
+
 func (p *ProcessRunner) readPipe(origin string, r io.ReadCloser) {
 	defer r.Close()
 	sc := bufio.NewScanner(r)
@@ -225,58 +315,104 @@ func (p *ProcessRunner) readPipe(origin string, r io.ReadCloser) {
 	sc.Buffer(buf, 1024*1024)
 	for sc.Scan() {
 		line := sc.Text()
-		p.logCh <- LogLine{Time: time.Now(), Origin: origin, Line: line}
-		if origin == "stdout" && p.readyPrefix != "" && strings.HasPrefix(line, p.readyPrefix) {
-			p.ready = true
-			p.emit(ProcEvent{Time: time.Now(), Type: ProcReady, Note: line})
+		if origin == "stdout" && strings.HasPrefix(line, contracts.ControlFramePrefix) {
+			p.handleControl(line)
+			continue
+		}
+		p.logCh <- LogLine{Time: p.clock.Now(), Origin: origin, Line: line}
+		if origin == "stdout" && !p.handshakeRequired && p.readyPrefix != "" && strings.HasPrefix(line, p.readyPrefix) {
+			p.ready.Store(true)
+			p.emit(ProcEvent{Time: p.clock.Now(), Type: ProcReady, Note: line})
 		}
 	}
 }
 
-func (p *ProcessRunner) Ready() bool { return p.ready }
+func (p *ProcessRunner) Ready() bool { return p.ready.Load() }
 
-// WaitReady ожидает готовности по stdout (READY) или по HTTP-пробе, что наступит раньше.
+// Pid — pid запущенного процесса (он же группа процессов); 0 — процесс не запускался.
+func (p *ProcessRunner) Pid() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cmd == nil || p.cmd.Process == nil {
+		return 0
+	}
+	return p.cmd.Process.Pid
+}
+
+// Done закрывается после завершения процесса (все строки логов к этому моменту уже отданы).
+func (p *ProcessRunner) Done() <-chan struct{} { return p.done }
+
+// ExitStatus возвращает код и ошибку завершения; имеет смысл после закрытия Done().
+func (p *ProcessRunner) ExitStatus() (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.exitCode, p.exitErr
+}
+
+// WaitReady ожидает готовности по stdout (READY), handshake-кадру hello или по HTTP-пробе,
+// что наступит раньше. С WithHandshakeRequired готовность даёт только hello.
+// Если процесс завершился раньше, возвращает ошибку сразу.
 func (p *ProcessRunner) WaitReady(ctx context.Context, timeout time.Duration) error {
 	if timeout <= 0 {
 		timeout = 10 * time.Second
 	}
-	ctx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
+	deadline := p.clock.NewTimer(timeout)
+	defer deadline.Stop()
 
-	ticker := time.NewTicker(200 * time.Millisecond)
-	defer ticker.Stop()
+	attempt, pause := 1, p.probe.Next(1, 0)
+	timer := p.clock.NewTimer(pause)
+	defer timer.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return errors.New("wait ready: timeout or cancelled")
-		case <-ticker.C:
-			if p.ready {
+		case <-deadline.C():
+			return errors.New("wait ready: timeout or cancelled")
+		case <-p.done:
+			if p.ready.Load() {
 				return nil
 			}
-			if p.healthURL != "" {
+			code, _ := p.ExitStatus()
+			return fmt.Errorf("wait ready: process exited with code %d", code)
+		case <-timer.C():
+			attempt++
+			pause = p.probe.Next(attempt, pause)
+			timer.Reset(pause)
+			if p.ready.Load() {
+				return nil
+			}
+			if p.healthURL != "" && !p.handshakeRequired {
 				cl := &http.Client{Timeout: 1 * time.Second}
 				req, _ := http.NewRequestWithContext(ctx, http.MethodGet, p.healthURL, nil)
 				resp, err := cl.Do(req)
 				if err == nil && resp.StatusCode >= 200 && resp.StatusCode < 300 {
-					p.emit(ProcEvent{Time: time.Now(), Type: ProcProbeOK, Note: p.healthURL})
+					p.emit(ProcEvent{Time: p.clock.Now(), Type: ProcProbeOK, Note: p.healthURL})
 					return nil
 				}
-				p.emit(ProcEvent{Time: time.Now(), Type: ProcProbeKO, Note: p.healthURL, Err: err})
+				p.emit(ProcEvent{Time: p.clock.Now(), Type: ProcProbeKO, Note: p.healthURL, Err: err})
 			}
 		}
 	}
 }
 
-// Stop посылает мягкий сигнал завершения процессу.
+// Stop посылает мягкий сигнал завершения (SIGTERM) группе процесса и не ждёт выхода.
+// Для остановки с ожиданием и эскалацией см. Shutdown.
 func (p *ProcessRunner) Stop() error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	if p.cmd == nil || p.cmd.Process == nil {
 		return nil
 	}
-	if runtime.GOOS == "windows" {
-		return p.cmd.Process.Kill()
+	return terminateGroup(p.cmd.Process)
+}
+
+// Kill немедленно завершает группу процесса (SIGKILL).
+func (p *ProcessRunner) Kill() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cmd == nil || p.cmd.Process == nil {
+		return nil
 	}
-	return p.cmd.Process.Signal(syscall.SIGTERM)
+	return killGroup(p.cmd.Process)
 }
@@ -0,0 +1,204 @@
+//go:build !windows
+
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitDone ждёт Done() не дольше секунды реального времени.
+func waitDone(t *testing.T, p *ProcessRunner) {
+	t.Helper()
+	select {
+	case <-p.Done():
+	case <-time.After(time.Second):
+		t.Fatal("process runner is not done")
+	}
+}
+
+// Все строки stdout/stderr отданы к закрытию Done().
+func TestProcessRunnerLogs(t *testing.T) {
+	p := NewProcessRunner("/bin/sh", []string{"-c", "echo out; echo err >&2; echo READY"}, WithProcClock(NewFakeClock(time.Time{})))
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for l := range p.Logs() {
+		got[l.Line] = l.Origin
+	}
+	waitDone(t, p)
+	if got["out"] != "stdout" || got["err"] != "stderr" || got["READY"] != "stdout" {
+		t.Fatalf("logs = %v", got)
+	}
+	if !p.Ready() {
+		t.Fatal("READY line did not mark the process ready")
+	}
+}
+
+// Потомок, унаследовавший stdout, держит пайп, но Done() закрывается через pipeDrainTimeout после выхода процесса.
+func TestProcessRunnerDoneWithInheritedPipes(t *testing.T) {
+	clock := NewFakeClock(time.Time{})
+	p := NewProcessRunner("/bin/sh", []string{"-c", "sleep 30 & echo started"}, WithProcClock(clock))
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Kill() // sleep живёт в группе и после выхода лидера
+
+	if l := <-p.Logs(); l.Line != "started" {
+		t.Fatalf("first line = %q, want started", l.Line)
+	}
+	clock.BlockUntil(1) // процесс вышел, пайпы дочитываются
+	select {
+	case <-p.Done():
+		t.Fatal("Done closed while a descendant still holds stdout")
+	default:
+	}
+	clock.Advance(pipeDrainTimeout)
+	waitDone(t, p)
+	if code, err := p.ExitStatus(); code != 0 || err != nil {
+		t.Fatalf("exit = %d, %v; want 0", code, err)
+	}
+}
+
+// startReady запускает sh-скрипт и ждёт его строку READY.
+func startReady(t *testing.T, script string, opts ...PROption) (*ProcessRunner, chan ProcEvent) {
+	t.Helper()
+	events := make(chan ProcEvent, 16)
+	opts = append(opts, WithProcEventHook(func(e ProcEvent) { events <- e }))
+	p := NewProcessRunner("/bin/sh", []string{"-c", script}, opts...)
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = p.Kill() })
+	for l := range p.Logs() {
+		if l.Line == "READY" {
+			return p, events
+		}
+	}
+	t.Fatal("process exited before READY")
+	return nil, nil
+}
+
+// eventTrail — события после start в виде type[:note] до exit включительно.
+func eventTrail(t *testing.T, events chan ProcEvent) []string {
+	t.Helper()
+	var trail []string
+	for {
+		select {
+		case e := <-events:
+			switch e.Type {
+			case ProcStart, ProcReady:
+				continue
+			case ProcSignal:
+				trail = append(trail, string(e.Type)+":"+e.Note)
+			default:
+				trail = append(trail, string(e.Type))
+			}
+			if e.Type == ProcExit {
+				return trail
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no exit event, got %v", trail)
+		}
+	}
+}
+
+type shutdownResult struct {
+	info ExitInfo
+	err  error
+}
+
+func shutdownAsync(p *ProcessRunner, grace time.Duration) chan shutdownResult {
+	res := make(chan shutdownResult, 1)
+	go func() {
+		info, err := p.Shutdown(context.Background(), grace)
+		res <- shutdownResult{info, err}
+	}()
+	return res
+}
+
+func TestShutdownGraceful(t *testing.T) {
+	p, events := startReady(t, "echo READY; exec sleep 30", WithProcClock(NewFakeClock(time.Time{})))
+	info, err := p.Shutdown(context.Background(), 5*time.Second)
+	if err != nil || info.Signal != "SIGTERM" || info.Killed {
+		t.Fatalf("Shutdown = %+v, %v; want SIGTERM without escalation", info, err)
+	}
+	if got := strings.Join(eventTrail(t, events), " "); got != "signal:SIGTERM exit" {
+		t.Fatalf("events = %s", got)
+	}
+}
+
+// Процесс игнорирует SIGTERM: SIGKILL приходит только по истечении grace на часах раннера.
+func TestShutdownEscalates(t *testing.T) {
+	clock := NewFakeClock(time.Time{})
+	p, events := startReady(t, "trap '' TERM; echo READY; sleep 30", WithProcClock(clock))
+	res := shutdownAsync(p, 5*time.Second)
+
+	clock.BlockUntil(1) // grace
+	clock.Advance(4 * time.Second)
+	select {
+	case r := <-res:
+		t.Fatalf("Shutdown returned before grace expired: %+v", r)
+	case <-time.After(20 * time.Millisecond):
+	}
+	clock.Advance(time.Second)
+
+	r := <-res
+	if r.err != nil || r.info.Signal != "SIGKILL" || !r.info.Killed {
+		t.Fatalf("Shutdown = %+v, %v; want SIGKILL with escalation", r.info, r.err)
+	}
+	want := "signal:SIGTERM grace_expired signal:SIGKILL exit"
+	if got := strings.Join(eventTrail(t, events), " "); got != want {
+		t.Fatalf("events = %s, want %s", got, want)
+	}
+}
+
+// Процесс вышел по SIGTERM, а его потомок — нет: Shutdown ждёт grace и убивает группу.
+func TestShutdownKillsLeftoverGroup(t *testing.T) {
+	clock := NewFakeClock(time.Time{})
+	pidFile := filepath.Join(t.TempDir(), "pid")
+	// потомок сам сообщает pid, когда SIGTERM уже игнорируется
+	p, _ := startReady(t, "(trap '' TERM; sh -c 'echo $PPID' >"+pidFile+"; exec sleep 30) >/dev/null 2>&1 & "+
+		"while [ ! -s "+pidFile+" ]; do sleep 0.01; done; echo READY; wait", WithProcClock(clock))
+	b, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	child, _ := strconv.Atoi(strings.TrimSpace(string(b)))
+
+	res := shutdownAsync(p, 5*time.Second)
+	waitDone(t, p)
+	clock.BlockUntil(2) // grace и опрос группы
+	select {
+	case r := <-res:
+		t.Fatalf("Shutdown returned while the group is alive: %+v", r)
+	case <-time.After(20 * time.Millisecond):
+	}
+	if !running(child) {
+		t.Fatal("descendant died on SIGTERM; the test needs it to survive")
+	}
+	clock.Advance(5 * time.Second)
+
+	r := <-res
+	if r.err != nil || !r.info.Killed || r.info.Signal != "SIGTERM" {
+		t.Fatalf("Shutdown = %+v, %v; want leader killed by SIGTERM and escalation", r.info, r.err)
+	}
+	eventually(t, "descendant killed", func() bool { return !running(child) })
+}
+
+// running — процесс pid существует и не зомби.
+func running(pid int) bool {
+	b, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return false
+	}
+	i := bytes.LastIndexByte(b, ')')
+	return i < 0 || i+2 >= len(b) || b[i+2] != 'Z'
+}
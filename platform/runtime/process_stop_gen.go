@@ -0,0 +1,125 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	// DefaultStopGrace — сколько Shutdown ждёт выхода после SIGTERM по умолчанию.
+	DefaultStopGrace = 10 * time.Second
+	// killWait — сколько ждём выхода после SIGKILL, прежде чем сдаться.
+	killWait = 5 * time.Second
+	// groupPollInterval — как часто Shutdown проверяет, остались ли в группе потомки.
+	groupPollInterval = 100 * time.Millisecond
+)
+
+// ExitInfo — итог завершения процесса.
+type ExitInfo struct {
+	Code   int    // код выхода; -1, если процесс убит сигналом
+	Signal string // сигнал, которым завершён процесс ("" — вышел сам)
+	Killed bool   // пришлось эскалировать до SIGKILL
+	Limit  string // превышенный лимит ресурсов (см. ResourceLimits)
+	Err    error  // ошибка cmd.Wait
+}
+
+func (e ExitInfo) String() string {
+	if e.Limit != "" {
+		return e.Limit
+	}
+	if e.Signal != "" {
+		return "killed by " + e.Signal
+	}
+	return fmt.Sprintf("exited with code %d", e.Code)
+}
+
+// Exit возвращает итог завершения; имеет смысл после закрытия Done().
+func (p *ProcessRunner) Exit() ExitInfo {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return ExitInfo{Code: p.exitCode, Signal: p.exitSignal, Killed: p.escalated.Load(), Limit: p.exitLimit, Err: p.exitErr}
+}
+
+// Shutdown останавливает процесс вместе с его группой: SIGTERM, ожидание grace
+// (или отмены ctx), затем SIGKILL. Выход самого процесса не завершает ожидание, пока в группе
+// остаются потомки: по истечении grace SIGKILL получает вся группа. Возвращает итог завершения.
+// Каждый шаг сообщается через ProcEvent (ProcSignal, ProcGraceExpired, ProcExit).
+func (p *ProcessRunner) Shutdown(ctx context.Context, grace time.Duration) (ExitInfo, error) {
+	p.mu.Lock()
+	started := p.cmd != nil && p.cmd.Process != nil
+	var pgid int
+	if started {
+		pgid = p.cmd.Process.Pid
+	}
+	p.mu.Unlock()
+	if !started {
+		return ExitInfo{}, errors.New("shutdown: process not started")
+	}
+	if p.exited() && !groupAlive(pgid) {
+		return p.Exit(), nil
+	}
+	if grace <= 0 {
+		grace = DefaultStopGrace
+	}
+
+	p.emit(ProcEvent{Time: p.clock.Now(), Type: ProcSignal, Note: "SIGTERM", Err: signalErr(p.Stop())})
+	t := p.clock.NewTimer(grace)
+	defer t.Stop()
+	// после выхода процесса группа опрашивается, пока не опустеет или не истечёт grace
+	var poll <-chan time.Time
+	done := p.done
+	for expired := false; !expired; {
+		select {
+		case <-done:
+			if !groupAlive(pgid) {
+				return p.Exit(), nil
+			}
+			done = nil
+			tk := p.clock.NewTicker(groupPollInterval)
+			defer tk.Stop()
+			poll = tk.C()
+		case <-poll:
+			if !groupAlive(pgid) {
+				return p.Exit(), nil
+			}
+		case <-t.C():
+			p.emit(ProcEvent{Time: p.clock.Now(), Type: ProcGraceExpired, Note: grace.String()})
+			expired = true
+		case <-ctx.Done():
+			p.emit(ProcEvent{Time: p.clock.Now(), Type: ProcGraceExpired, Note: "context done", Err: ctx.Err()})
+			expired = true
+		}
+	}
+
+	p.escalated.Store(true)
+	p.emit(ProcEvent{Time: p.clock.Now(), Type: ProcSignal, Note: "SIGKILL", Err: signalErr(p.Kill())})
+	kt := p.clock.NewTimer(killWait)
+	defer kt.Stop()
+	select {
+	case <-p.done:
+		return p.Exit(), nil
+	case <-kt.C():
+		return ExitInfo{Killed: true}, fmt.Errorf("shutdown: process did not exit %s after SIGKILL", killWait)
+	}
+}
+
+// exited сообщает, закрыт ли уже Done().
+func (p *ProcessRunner) exited() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// signalErr отбрасывает ошибку "процесс уже завершён" — для Shutdown это не сбой.
+func signalErr(err error) error {
+	if errors.Is(err, os.ErrProcessDone) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,345 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ResourceLimits — лимиты ресурсов дочернего процесса (0 — без ограничения).
+// Разбираются из Manifest.Resources / DomainSpec.Resources (см. ParseResourceLimits).
+type ResourceLimits struct {
+	MemoryBytes uint64 `json:"memory_bytes,omitempty"`
+	CPUSeconds  uint64 `json:"cpu_seconds,omitempty"`
+	OpenFiles   uint64 `json:"open_files,omitempty"`
+	Processes   uint64 `json:"processes,omitempty"`
+}
+
+// ResourceUsage — текущее потребление процесса (из /proc и cgroup, если он есть).
+type ResourceUsage struct {
+	MemoryBytes uint64    `json:"memory_bytes"` // RSS процесса или memory.current cgroup
+	CPUSeconds  float64   `json:"cpu_seconds"`
+	OpenFiles   int       `json:"open_files"`
+	Threads     int       `json:"threads"`
+	Processes   int       `json:"processes,omitempty"` // pids.current cgroup
+	Cgroup      string    `json:"cgroup,omitempty"`
+	SampledAt   time.Time `json:"sampled_at"`
+}
+
+// LimitEnforcement — чем применены лимиты процесса.
+type LimitEnforcement struct {
+	Cgroup      string   `json:"cgroup,omitempty"`       // группа процесса, если cgroup доступна
+	CgroupError string   `json:"cgroup_error,omitempty"` // почему cgroup недоступна
+	Fallback    []string `json:"fallback,omitempty"`     // лимиты, которые вместо cgroup ограничены rlimit
+}
+
+// IsZero сообщает, что ни один лимит не задан.
+func (l ResourceLimits) IsZero() bool { return l == ResourceLimits{} }
+
+// Tighten возвращает лимиты, где каждое поле — более строгое из двух.
+func (l ResourceLimits) Tighten(o ResourceLimits) ResourceLimits {
+	tighter := func(a, b uint64) uint64 {
+		if a == 0 || (b != 0 && b < a) {
+			return b
+		}
+		return a
+	}
+	return ResourceLimits{
+		MemoryBytes: tighter(l.MemoryBytes, o.MemoryBytes),
+		CPUSeconds:  tighter(l.CPUSeconds, o.CPUSeconds),
+		OpenFiles:   tighter(l.OpenFiles, o.OpenFiles),
+		Processes:   tighter(l.Processes, o.Processes),
+	}
+}
+
+// ParseResourceLimits разбирает свободную карту ресурсов манифеста.
+// Ключи: memory ("512Mi", "1G", байты), cpu_time ("30s", секунды), open_files, processes.
+// Прочие ключи игнорируются: Resources может описывать и то, что Root не контролирует.
+func ParseResourceLimits(m map[string]any) (ResourceLimits, error) {
+	var l ResourceLimits
+	var err error
+	for k, v := range m {
+		switch k {
+		case "memory":
+			l.MemoryBytes, err = parseBytes(v)
+		case "cpu_time":
+			l.CPUSeconds, err = parseSeconds(v)
+		case "open_files":
+			l.OpenFiles, err = parseCount(v)
+		case "processes":
+			l.Processes, err = parseCount(v)
+		default:
+			continue
+		}
+		if err != nil {
+			return ResourceLimits{}, fmt.Errorf("resources.%s: %w", k, err)
+		}
+	}
+	return l, nil
+}
+
+func parseCount(v any) (uint64, error) {
+	switch n := v.(type) {
+	case int:
+		if n >= 0 {
+			return uint64(n), nil
+		}
+	case int64:
+		if n >= 0 {
+			return uint64(n), nil
+		}
+	case uint64:
+		return n, nil
+	case float64:
+		if n >= 0 && n == float64(uint64(n)) {
+			return uint64(n), nil
+		}
+	case string:
+		return strconv.ParseUint(strings.TrimSpace(n), 10, 64)
+	}
+	return 0, fmt.Errorf("invalid value %v", v)
+}
+
+var byteUnits = []struct {
+	suffix string
+	mult   uint64
+}{
+	{"Ki", 1 << 10}, {"Mi", 1 << 20}, {"Gi", 1 << 30}, {"Ti", 1 << 40},
+	{"KB", 1e3}, {"MB", 1e6}, {"GB", 1e9}, {"TB", 1e12},
+	{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30}, {"T", 1 << 40},
+}
+
+func parseBytes(v any) (uint64, error) {
+	s, ok := v.(string)
+	if !ok {
+		return parseCount(v)
+	}
+	s = strings.TrimSpace(s)
+	for _, u := range byteUnits {
+		if num, found := strings.CutSuffix(s, u.suffix); found {
+			n, err := strconv.ParseFloat(strings.TrimSpace(num), 64)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid size %q", s)
+			}
+			return uint64(n * float64(u.mult)), nil
+		}
+	}
+	n, err := strconv.ParseUint(strings.TrimSuffix(s, "B"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return n, nil
+}
+
+func parseSeconds(v any) (uint64, error) {
+	s, ok := v.(string)
+	if !ok {
+		return parseCount(v)
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil {
+		return parseCount(s)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	// RLIMIT_CPU задаётся в целых секундах; округляем вверх
+	return uint64((d + time.Second - 1) / time.Second), nil
+}
+
+func formatBytes(n uint64) string {
+	switch {
+	case n >= 1<<30 && n%(1<<30) == 0:
+		return fmt.Sprintf("%dGi", n>>30)
+	case n >= 1<<20 && n%(1<<20) == 0:
+		return fmt.Sprintf("%dMi", n>>20)
+	case n >= 1<<10 && n%(1<<10) == 0:
+		return fmt.Sprintf("%dKi", n>>10)
+	}
+	return strconv.FormatUint(n, 10)
+}
+
+// WithResourceLimits задаёт лимиты, под которыми процесс запускается (см. startLimitedLocked).
+func WithResourceLimits(l ResourceLimits) PROption {
+	return func(p *ProcessRunner) { p.limits = l }
+}
+
+// WithCgroupParent задаёт делегированную cgroup v2, в которой процессу создаётся группа rk-*
+// (см. newCgroup). Без неё memory и processes ограничиваются rlimit: RLIMIT_AS — виртуальная
+// память, а не реальное потребление; RLIMIT_NPROC — процессы пользователя целиком.
+func WithCgroupParent(dir string) PROption {
+	return func(p *ProcessRunner) { p.cgroupParent = dir }
+}
+
+// Enforcement сообщает, как применены лимиты: cgroup или откат на rlimit и почему.
+func (p *ProcessRunner) Enforcement() LimitEnforcement {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.enforcement
+}
+
+// Limits возвращает действующие лимиты процесса.
+func (p *ProcessRunner) Limits() ResourceLimits {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.limits
+}
+
+// ApplyLimits применяет новые лимиты к уже запущенному процессу
+// (например, объявленные в манифесте из handshake).
+func (p *ProcessRunner) ApplyLimits(l ResourceLimits) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cmd == nil || p.cmd.Process == nil {
+		return errors.New("apply limits: process not started")
+	}
+	p.limits = l
+	return p.applyLimitsLocked(p.cmd.Process.Pid)
+}
+
+// startLimitedLocked запускает cmd сразу под лимитами: процесс рождается в собственной cgroup
+// (clone в группу), а то, что cgroup не покрыла, ставит обёртка до exec программы (см. wrapRlimits) —
+// без лимитов процесс не живёт ни мгновения. Возвращает событие ProcLimits, которое Start отправит
+// после ProcStart. Вызывается под p.mu.
+func (p *ProcessRunner) startLimitedLocked(cmd *exec.Cmd) (ProcEvent, error) {
+	path, args := cmd.Path, cmd.Args
+	var notes []string
+	var enf LimitEnforcement
+	cg, err := newCgroup(p.cgroupParent)
+	if err != nil {
+		enf.CgroupError = err.Error()
+		notes = append(notes, "cgroup unavailable: "+err.Error())
+	} else if err := cg.set(p.limits); err != nil {
+		notes = append(notes, "cgroup: "+err.Error())
+	}
+	var limErr error
+	if cg != nil {
+		limErr = wrapRlimits(cmd, cg.uncovered(p.limits))
+		if err := cg.startIn(cmd); err != nil {
+			// ядро до 5.7 или нет прав на группу: запускаемся без cgroup, всё — через rlimit
+			cg.remove()
+			cg = nil
+			enf.CgroupError = "start in cgroup: " + err.Error()
+			notes = append(notes, "cgroup unavailable: "+enf.CgroupError)
+			cmd.Path, cmd.Args = path, args
+		}
+	}
+	rl := p.limits
+	if cg == nil {
+		limErr = wrapRlimits(cmd, rl)
+		if err := cmd.Start(); err != nil {
+			return ProcEvent{}, err
+		}
+	} else {
+		rl = cg.uncovered(p.limits)
+		notes = append(notes, "cgroup "+cg.dir)
+		enf.Cgroup = cg.dir
+	}
+	p.cgroup = cg
+	return p.limitsEventLocked(enf, rl, notes, limErr), nil
+}
+
+// applyLimitsLocked кладёт уже работающий процесс в собственную cgroup v2 (если она доступна
+// на запись), а то, что cgroup не покрыла, ограничивает через prlimit. Вызывается под p.mu.
+func (p *ProcessRunner) applyLimitsLocked(pid int) error {
+	var notes []string
+	var enf LimitEnforcement
+	if p.cgroup == nil {
+		cg, err := newCgroup(p.cgroupParent)
+		if err == nil {
+			if err = cg.add(pid); err != nil {
+				cg.remove()
+				cg = nil
+			}
+		}
+		if err != nil {
+			enf.CgroupError = err.Error()
+			notes = append(notes, "cgroup unavailable: "+err.Error())
+		}
+		p.cgroup = cg
+	}
+	rl := p.limits
+	if p.cgroup != nil {
+		if err := p.cgroup.set(p.limits); err != nil {
+			notes = append(notes, "cgroup: "+err.Error())
+		}
+		rl = p.cgroup.uncovered(p.limits)
+		notes = append(notes, "cgroup "+p.cgroup.dir)
+		enf.Cgroup = p.cgroup.dir
+	}
+	err := applyRlimits(pid, rl)
+	p.emit(p.limitsEventLocked(enf, rl, notes, err))
+	return err
+}
+
+// limitsEventLocked запоминает, чем применены лимиты (rl — то, что досталось rlimit),
+// и собирает событие ProcLimits.
+func (p *ProcessRunner) limitsEventLocked(enf LimitEnforcement, rl ResourceLimits, notes []string, err error) ProcEvent {
+	if rl.MemoryBytes > 0 {
+		enf.Fallback = append(enf.Fallback, "memory: RLIMIT_AS (virtual memory)")
+	}
+	if rl.Processes > 0 {
+		enf.Fallback = append(enf.Fallback, "processes: RLIMIT_NPROC (per user)")
+	}
+	if len(enf.Fallback) > 0 {
+		notes = append(notes, "fallback "+strings.Join(enf.Fallback, ", "))
+	}
+	p.enforcement = enf
+	return ProcEvent{Time: time.Now(), Type: ProcLimits, Note: strings.Join(notes, "; "), Err: err}
+}
+
+// uncovered возвращает лимиты, которые группа не держит и которые остаются на rlimit.
+func (c *cgroup) uncovered(l ResourceLimits) ResourceLimits {
+	if c.memory {
+		l.MemoryBytes = 0
+	}
+	if c.pids {
+		l.Processes = 0
+	}
+	return l
+}
+
+// Usage снимает текущее потребление ресурсов процессом.
+func (p *ProcessRunner) Usage() (ResourceUsage, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cmd == nil || p.cmd.Process == nil {
+		return ResourceUsage{}, errors.New("usage: process not started")
+	}
+	select {
+	case <-p.done:
+		return ResourceUsage{}, errors.New("usage: process exited")
+	default:
+	}
+	return readUsage(p.cmd.Process.Pid, p.cgroup)
+}
+
+// limitExceeded определяет по итогам завершения, был ли процесс убит за превышение лимита.
+// Вызывается наблюдателем после cmd.Wait, до удаления cgroup.
+func (p *ProcessRunner) limitExceeded(ps *os.ProcessState, sig string) string {
+	p.mu.Lock()
+	l, cg := p.limits, p.cgroup
+	p.mu.Unlock()
+	if l.IsZero() {
+		return ""
+	}
+	if cg != nil {
+		if l.MemoryBytes > 0 && cg.oomKills() > 0 {
+			return fmt.Sprintf("memory limit exceeded (%s, oom kill)", formatBytes(l.MemoryBytes))
+		}
+		if l.Processes > 0 && cg.pidsLimitHits() > 0 && ps != nil && !ps.Success() {
+			return fmt.Sprintf("process limit reached (%d)", l.Processes)
+		}
+	}
+	if l.CPUSeconds > 0 && ps != nil {
+		used := ps.UserTime() + ps.SystemTime()
+		if sig == "SIGXCPU" || (sig == "SIGKILL" && used >= time.Duration(l.CPUSeconds)*time.Second) {
+			return fmt.Sprintf("cpu time limit exceeded (%ds)", l.CPUSeconds)
+		}
+	}
+	return ""
+}
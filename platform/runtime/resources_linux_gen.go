@@ -0,0 +1,279 @@
+//go:build linux
+
+package runtime
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+const (
+	rlimitNproc = 6 // RLIMIT_NPROC нет в пакете syscall
+	// cpuKillGrace — запас между мягким (SIGXCPU) и жёстким (SIGKILL) лимитом CPU.
+	cpuKillGrace = 2
+	// clockTicks — USER_HZ для полей utime/stime в /proc/<pid>/stat.
+	clockTicks = 100
+	cgroupRoot = "/sys/fs/cgroup"
+)
+
+type rlimit struct{ Cur, Max uint64 }
+
+func prlimit(pid, resource int, lim rlimit) error {
+	if pid == 0 {
+		// себе — через Setrlimit: иначе syscall.Exec вернёт исходный RLIMIT_NOFILE, запомненный рантаймом
+		return syscall.Setrlimit(resource, &syscall.Rlimit{Cur: lim.Cur, Max: lim.Max})
+	}
+	_, _, errno := syscall.RawSyscall6(syscall.SYS_PRLIMIT64, uintptr(pid), uintptr(resource), uintptr(unsafe.Pointer(&lim)), 0, 0, 0)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// applyRlimits ставит rlimit-ы работающему процессу (prlimit); pid 0 — себе.
+// RLIMIT_AS ограничивает виртуальную память, RLIMIT_NPROC считает процессы пользователя целиком —
+// поэтому при доступной cgroup эти лимиты берёт на себя она.
+func applyRlimits(pid int, l ResourceLimits) error {
+	var errs []error
+	set := func(name string, res int, cur, max uint64) {
+		if err := prlimit(pid, res, rlimit{Cur: cur, Max: max}); err != nil {
+			errs = append(errs, fmt.Errorf("rlimit %s: %w", name, err))
+		}
+	}
+	if l.MemoryBytes > 0 {
+		set("memory", syscall.RLIMIT_AS, l.MemoryBytes, l.MemoryBytes)
+	}
+	if l.CPUSeconds > 0 {
+		set("cpu_time", syscall.RLIMIT_CPU, l.CPUSeconds, l.CPUSeconds+cpuKillGrace)
+	}
+	if l.OpenFiles > 0 {
+		set("open_files", syscall.RLIMIT_NOFILE, l.OpenFiles, l.OpenFiles)
+	}
+	if l.Processes > 0 {
+		set("processes", rlimitNproc, l.Processes, l.Processes)
+	}
+	return errors.Join(errs...)
+}
+
+// rlimitExecArg0 — argv[0] обёртки, которая ставит rlimit-ы себе и делает exec программы:
+// так лимиты действуют с первой инструкции процесса, а не после prlimit снаружи.
+const rlimitExecArg0 = "rk-rlimit-exec"
+
+func init() {
+	if len(os.Args) < 4 || os.Args[0] != rlimitExecArg0 {
+		return
+	}
+	// argv: rk-rlimit-exec <memory,cpu,files,procs> <path> <argv программы...>
+	var l ResourceLimits
+	_, err := fmt.Sscanf(os.Args[1], "%d,%d,%d,%d", &l.MemoryBytes, &l.CPUSeconds, &l.OpenFiles, &l.Processes)
+	if err == nil {
+		err = applyRlimits(0, l)
+	}
+	if err == nil {
+		err = syscall.Exec(os.Args[2], os.Args[3:], os.Environ())
+	}
+	fmt.Fprintf(os.Stderr, "%s: %s: %v\n", rlimitExecArg0, os.Args[2], err)
+	os.Exit(127)
+}
+
+// wrapRlimits подменяет запуск cmd обёрткой rlimitExecArg0 из текущего бинарника.
+// pid, группа процессов и файлы остаются теми же: обёртка заменяется программой через exec.
+func wrapRlimits(cmd *exec.Cmd, l ResourceLimits) error {
+	if l.IsZero() || cmd.Err != nil {
+		return nil // с ошибкой LookPath пусть падает сам Start
+	}
+	spec := fmt.Sprintf("%d,%d,%d,%d", l.MemoryBytes, l.CPUSeconds, l.OpenFiles, l.Processes)
+	cmd.Args = append([]string{rlimitExecArg0, spec, cmd.Path}, cmd.Args...)
+	cmd.Path = "/proc/self/exe"
+	return nil
+}
+
+// cgroup — собственная cgroup v2 дочернего процесса.
+type cgroup struct {
+	dir    string
+	memory bool // memory.max доступен
+	pids   bool // pids.max доступен
+}
+
+// newCgroup создаёт пустую группу rk-* в делегированной cgroup v2 parent; процесс попадает в неё
+// при запуске (startIn) или переносом (add). parent (абсолютный путь или путь от /sys/fs/cgroup)
+// не должна содержать своих процессов — по правилу cgroup v2 "no internal processes" иначе нельзя
+// включить контроллеры для дочерних групп. Поэтому cgroup самого rk не годится: нужна отдельная
+// делегированная группа (например, systemd Delegate=yes).
+func newCgroup(parent string) (*cgroup, error) {
+	if parent == "" {
+		return nil, errors.New("cgroup parent is not configured")
+	}
+	if !filepath.IsAbs(parent) {
+		parent = filepath.Join(cgroupRoot, parent)
+	}
+	if _, err := os.Stat(filepath.Join(parent, "cgroup.controllers")); err != nil {
+		return nil, fmt.Errorf("%s is not a cgroup v2 directory", parent)
+	}
+	if parent != cgroupRoot {
+		procs, err := os.ReadFile(filepath.Join(parent, "cgroup.procs"))
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(procs)) > 0 {
+			return nil, fmt.Errorf("cgroup %s has member processes; delegate an empty cgroup", parent)
+		}
+	}
+	// контроллеры включаются по одному: недоступный (memory.max/pids.max не появится) уйдёт в rlimit
+	for _, c := range []string{"+memory", "+pids"} {
+		_ = os.WriteFile(filepath.Join(parent, "cgroup.subtree_control"), []byte(c), 0)
+	}
+
+	dir, err := os.MkdirTemp(parent, "rk-")
+	if err != nil {
+		return nil, err
+	}
+	cg := &cgroup{dir: dir}
+	_, err = os.Stat(filepath.Join(dir, "memory.max"))
+	cg.memory = err == nil
+	_, err = os.Stat(filepath.Join(dir, "pids.max"))
+	cg.pids = err == nil
+	return cg, nil
+}
+
+// startIn запускает cmd сразу внутри группы (clone с CLONE_INTO_CGROUP, Linux 5.7+).
+func (c *cgroup) startIn(cmd *exec.Cmd) error {
+	f, err := os.Open(c.dir)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.UseCgroupFD, cmd.SysProcAttr.CgroupFD = true, int(f.Fd())
+	if err := cmd.Start(); err != nil {
+		cmd.SysProcAttr.UseCgroupFD, cmd.SysProcAttr.CgroupFD = false, 0
+		return err
+	}
+	return nil
+}
+
+// add переносит в группу уже работающий процесс.
+func (c *cgroup) add(pid int) error {
+	return os.WriteFile(filepath.Join(c.dir, "cgroup.procs"), []byte(strconv.Itoa(pid)), 0)
+}
+
+func (c *cgroup) set(l ResourceLimits) error {
+	value := func(n uint64) []byte {
+		if n == 0 {
+			return []byte("max")
+		}
+		return []byte(strconv.FormatUint(n, 10))
+	}
+	var errs []error
+	if c.memory {
+		if err := os.WriteFile(filepath.Join(c.dir, "memory.max"), value(l.MemoryBytes), 0); err != nil {
+			c.memory = false
+			errs = append(errs, err)
+		}
+	}
+	if c.pids {
+		if err := os.WriteFile(filepath.Join(c.dir, "pids.max"), value(l.Processes), 0); err != nil {
+			c.pids = false
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *cgroup) oomKills() int { return c.event("memory.events", "oom_kill") }
+
+func (c *cgroup) pidsLimitHits() int { return c.event("pids.events", "max") }
+
+func (c *cgroup) event(file, key string) int {
+	b, err := os.ReadFile(filepath.Join(c.dir, file))
+	if err != nil {
+		return 0
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		if v, ok := strings.CutPrefix(line, key+" "); ok {
+			n, _ := strconv.Atoi(strings.TrimSpace(v))
+			return n
+		}
+	}
+	return 0
+}
+
+// remove удаляет пустую cgroup после выхода процесса.
+func (c *cgroup) remove() { _ = os.Remove(c.dir) }
+
+func readUint(path string) (uint64, bool) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(strings.TrimSpace(string(b)), 10, 64)
+	return n, err == nil
+}
+
+// readUsage читает потребление из /proc/<pid> и, если есть, из cgroup процесса.
+func readUsage(pid int, cg *cgroup) (ResourceUsage, error) {
+	u := ResourceUsage{SampledAt: time.Now()}
+	proc := fmt.Sprintf("/proc/%d", pid)
+
+	status, err := os.ReadFile(proc + "/status")
+	if err != nil {
+		return u, err
+	}
+	sc := bufio.NewScanner(bytes.NewReader(status))
+	for sc.Scan() {
+		key, val, ok := strings.Cut(sc.Text(), ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(val)
+		if len(fields) == 0 {
+			continue
+		}
+		switch key {
+		case "VmRSS":
+			if kb, err := strconv.ParseUint(fields[0], 10, 64); err == nil {
+				u.MemoryBytes = kb * 1024
+			}
+		case "Threads":
+			u.Threads, _ = strconv.Atoi(fields[0])
+		}
+	}
+
+	if stat, err := os.ReadFile(proc + "/stat"); err == nil {
+		// поля после "(comm)": state — [0], utime — [11], stime — [12]
+		if i := bytes.LastIndexByte(stat, ')'); i >= 0 {
+			f := strings.Fields(string(stat[i+1:]))
+			if len(f) > 12 {
+				ut, _ := strconv.ParseUint(f[11], 10, 64)
+				st, _ := strconv.ParseUint(f[12], 10, 64)
+				u.CPUSeconds = float64(ut+st) / clockTicks
+			}
+		}
+	}
+	if fds, err := os.ReadDir(proc + "/fd"); err == nil {
+		u.OpenFiles = len(fds)
+	}
+
+	if cg != nil {
+		u.Cgroup = cg.dir
+		if n, ok := readUint(filepath.Join(cg.dir, "memory.current")); ok {
+			u.MemoryBytes = n
+		}
+		if n, ok := readUint(filepath.Join(cg.dir, "pids.current")); ok {
+			u.Processes = int(n)
+		}
+	}
+	return u, nil
+}
@@ -0,0 +1,49 @@
+//go:build linux
+
+package runtime
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// rlimit-ы стоят уже на первой инструкции программы, а pid процесса — тот же, что у раннера.
+func TestStartUnderRlimits(t *testing.T) {
+	events := make(chan ProcEvent, 16)
+	p := NewProcessRunner("/bin/sh", []string{"-c", "echo $$; ulimit -n; ulimit -t"},
+		WithResourceLimits(ResourceLimits{OpenFiles: 64, CPUSeconds: 30}),
+		WithProcClock(NewFakeClock(time.Time{})),
+		WithProcEventHook(func(e ProcEvent) { events <- e }))
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for l := range p.Logs() {
+		lines = append(lines, l.Origin+": "+l.Line)
+	}
+	waitDone(t, p)
+
+	want := []string{"stdout: " + strconv.Itoa(p.cmd.Process.Pid), "stdout: 64", "stdout: 30"}
+	if len(lines) != len(want) {
+		t.Fatalf("output = %q, want %q", lines, want)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Fatalf("output = %q, want %q", lines, want)
+		}
+	}
+	if code, err := p.ExitStatus(); code != 0 || err != nil {
+		t.Fatalf("exit = %d, %v; want 0", code, err)
+	}
+	if e := <-events; e.Type != ProcStart {
+		t.Fatalf("first event = %s, want start", e.Type)
+	}
+	if e := <-events; e.Type != ProcLimits || e.Err != nil {
+		t.Fatalf("second event = %+v, want limits without error", e)
+	}
+	if enf := p.Enforcement(); enf.Cgroup != "" || enf.CgroupError == "" {
+		t.Fatalf("enforcement = %+v, want rlimit without cgroup", enf)
+	}
+}
@@ -0,0 +1,42 @@
+//go:build !linux
+
+package runtime
+
+import (
+	"errors"
+	"os/exec"
+)
+
+var errLimitsUnsupported = errors.New("resource limits are supported on linux only")
+
+func applyRlimits(pid int, l ResourceLimits) error {
+	if l.IsZero() {
+		return nil
+	}
+	return errLimitsUnsupported
+}
+
+func wrapRlimits(cmd *exec.Cmd, l ResourceLimits) error {
+	if l.IsZero() {
+		return nil
+	}
+	return errLimitsUnsupported
+}
+
+type cgroup struct {
+	dir          string
+	memory, pids bool
+}
+
+func newCgroup(parent string) (*cgroup, error) { return nil, errLimitsUnsupported }
+
+func (c *cgroup) startIn(cmd *exec.Cmd) error { return errLimitsUnsupported }
+func (c *cgroup) add(pid int) error           { return errLimitsUnsupported }
+func (c *cgroup) set(l ResourceLimits) error  { return errLimitsUnsupported }
+func (c *cgroup) oomKills() int               { return 0 }
+func (c *cgroup) pidsLimitHits() int          { return 0 }
+func (c *cgroup) remove()                     {}
+
+func readUsage(pid int, cg *cgroup) (ResourceUsage, error) {
+	return ResourceUsage{}, errLimitsUnsupported
+}
@@ -0,0 +1,89 @@
+package runtime
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ParseRestartPolicy разбирает политику из конфига; пустая строка — Permanent.
+func ParseRestartPolicy(s string) (RestartPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "permanent":
+		return Permanent, nil
+	case "transient":
+		return Transient, nil
+	case "temporary":
+		return Temporary, nil
+	default:
+		return Permanent, fmt.Errorf("unknown restart policy %q", s)
+	}
+}
+
+// ShouldRestart решает, нужен ли рестарт после завершения (failed — ошибка/паника/ненулевой код).
+func (p RestartPolicy) ShouldRestart(failed bool) bool {
+	switch p {
+	case Permanent:
+		return true
+	case Transient:
+		return failed
+	default:
+		return false
+	}
+}
+
+// RestartIntensity — лимит "не больше Max рестартов за окно Window". Max <= 0 — без лимита.
+type RestartIntensity struct {
+	Max    int
+	Window time.Duration
+}
+
+// RestartTracker считает рестарты в скользящем окне RestartIntensity.
+type RestartTracker struct {
+	mu    sync.Mutex
+	limit RestartIntensity
+	times []time.Time
+}
+
+func NewRestartTracker(limit RestartIntensity) *RestartTracker {
+	return &RestartTracker{limit: limit}
+}
+
+// Record фиксирует рестарт в момент now и сообщает, превышен ли лимит.
+func (t *RestartTracker) Record(now time.Time) (exceeded bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.limit.Max <= 0 {
+		return false
+	}
+	t.prune(now)
+	t.times = append(t.times, now)
+	return len(t.times) > t.limit.Max
+}
+
+// Count — число рестартов в текущем окне.
+func (t *RestartTracker) Count(now time.Time) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.prune(now)
+	return len(t.times)
+}
+
+// Reset забывает историю рестартов.
+func (t *RestartTracker) Reset() {
+	t.mu.Lock()
+	t.times = nil
+	t.mu.Unlock()
+}
+
+func (t *RestartTracker) prune(now time.Time) {
+	if t.limit.Window <= 0 {
+		return
+	}
+	cut := 0
+	for cut < len(t.times) && now.Sub(t.times[cut]) > t.limit.Window {
+		cut++
+	}
+	t.times = t.times[cut:]
+}
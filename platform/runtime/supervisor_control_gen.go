@@ -0,0 +1,150 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrUnknownWorker     = errors.New("unknown worker")
+	ErrWorkerStopping    = errors.New("worker is stopping")
+	ErrSupervisorStopped = errors.New("supervisor stopped")
+)
+
+// WorkerSuspended — воркер остановлен через Suspend и ждёт Resume.
+const WorkerSuspended WorkerState = "suspended"
+
+// controlLocked находит воркер для ручной команды.
+func (s *Supervisor) controlLocked(name string) (*worker, error) {
+	if s.closing {
+		return nil, ErrSupervisorStopped
+	}
+	w := s.findLocked(name)
+	if w == nil {
+		return nil, fmt.Errorf("%w %q", ErrUnknownWorker, name)
+	}
+	if w.stopped || w.state == WorkerStopping {
+		return nil, fmt.Errorf("%w: %s", ErrWorkerStopping, name)
+	}
+	return w, nil
+}
+
+// restartLocked перезапускает воркер сразу: работающий — через отмену ctx (см. handleExit),
+// ждущий рестарта или приостановленный — запуском нового экземпляра.
+func (s *Supervisor) restartLocked(w *worker) []Event {
+	w.suspended = false
+	if w.state == WorkerRunning {
+		w.manual = true
+		w.cancel()
+		return nil
+	}
+	w.gen++ // запланированный по backoff рестарт больше не нужен
+	return s.launchLocked(w)
+}
+
+// Restart перезапускает воркер сразу, без backoff; ручные рестарты не считаются в лимите.
+func (s *Supervisor) Restart(name string) error {
+	s.mu.Lock()
+	w, err := s.controlLocked(name)
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	running := w.state == WorkerRunning
+	started := s.restartLocked(w)
+	s.mu.Unlock()
+	if !running {
+		s.emit(Event{Time: s.clock.Now(), Worker: name, Type: EventRestart})
+	}
+	s.emitAll(started)
+	return nil
+}
+
+// Suspend останавливает воркер, оставляя его в супервизоре: он не перезапускается до Resume.
+func (s *Supervisor) Suspend(name string) error {
+	s.mu.Lock()
+	w, err := s.controlLocked(name)
+	if err != nil || w.suspended {
+		s.mu.Unlock()
+		return err
+	}
+	w.suspended, w.manual = true, false
+	if w.state == WorkerRunning {
+		// событие suspend отправит handleExit после выхода
+		w.cancel()
+		s.mu.Unlock()
+		return nil
+	}
+	w.gen++
+	w.state = WorkerSuspended
+	s.mu.Unlock()
+	s.emit(Event{Time: s.clock.Now(), Worker: name, Type: EventSuspend})
+	return nil
+}
+
+// Resume запускает приостановленный воркер.
+func (s *Supervisor) Resume(name string) error {
+	s.mu.Lock()
+	w, err := s.controlLocked(name)
+	if err != nil || !w.suspended {
+		s.mu.Unlock()
+		return err
+	}
+	started := s.restartLocked(w)
+	s.mu.Unlock()
+	s.emit(Event{Time: s.clock.Now(), Worker: name, Type: EventResume})
+	s.emitAll(started)
+	return nil
+}
+
+// Replace подменяет спецификацию воркера spec.Name на месте (порядок запуска сохраняется)
+// и перезапускает его с новой; приостановленный воркер получит её при Resume.
+func (s *Supervisor) Replace(spec WorkerSpec) error {
+	if spec.Fn == nil || spec.Name == "" {
+		return fmt.Errorf("invalid worker spec")
+	}
+	s.mu.Lock()
+	w, err := s.controlLocked(spec.Name)
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	w.spec.Policy, w.spec.Backoff, w.spec.Shutdown = spec.Policy, spec.Backoff, spec.Shutdown
+	w.spec.Fn, w.spec.nested = spec.Fn, spec.nested
+	var started []Event
+	running, suspended := w.state == WorkerRunning, w.suspended
+	if !suspended {
+		started = s.restartLocked(w)
+	}
+	s.mu.Unlock()
+	if !suspended && !running {
+		s.emit(Event{Time: s.clock.Now(), Worker: spec.Name, Type: EventRestart})
+	}
+	s.emitAll(started)
+	return nil
+}
+
+// Lookup находит супервизор и имя воркера по пути вида "site-workers/fk-hello-http"
+// (промежуточные элементы — воркеры-супервизоры из SupervisorSpec).
+func (s *Supervisor) Lookup(path string) (*Supervisor, string, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	cur := s
+	for _, p := range parts[:len(parts)-1] {
+		cur.mu.Lock()
+		var nested func() *Supervisor
+		if w := cur.findLocked(p); w != nil {
+			nested = w.spec.nested
+		}
+		cur.mu.Unlock()
+		var sub *Supervisor
+		if nested != nil {
+			sub = nested()
+		}
+		if sub == nil {
+			return nil, "", fmt.Errorf("%w %q", ErrUnknownWorker, path)
+		}
+		cur = sub
+	}
+	return cur, parts[len(parts)-1], nil
+}
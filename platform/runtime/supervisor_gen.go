@@ -3,8 +3,9 @@ package runtime
 import (
 	"context"
 	"fmt"
-	"math/rand"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -33,82 +34,43 @@ func (p RestartPolicy) String() string {
 // WorkerFunc — функция воркера. Должна завершиться сама или по отмене ctx.
 type WorkerFunc func(ctx context.Context) error
 
-// BackoffPolicy — параметры экспоненциального backoff с джиттером.
-type BackoffPolicy struct {
-	Min    time.Duration // минимальная задержка (по умолчанию 100ms)
-	Max    time.Duration // максимальная задержка (по умолчанию 30s)
-	Factor float64       // множитель экспоненты (по умолчанию 2.0)
-	Jitter float64       // 0..1, доля случайного джиттера (по умолчанию 0.2)
-}
+// DefaultShutdownTimeout — сколько супервизор ждёт выхода воркера после отмены его ctx.
+const DefaultShutdownTimeout = 5 * time.Second
 
-func (b BackoffPolicy) withDefaults() BackoffPolicy {
-	out := b
-	if out.Min <= 0 {
-		out.Min = 100 * time.Millisecond
-	}
-	if out.Max <= 0 {
-		out.Max = 30 * time.Second
-	}
-	if out.Factor <= 0 {
-		out.Factor = 2.0
-	}
-	if out.Jitter < 0 {
-		out.Jitter = 0
-	}
-	if out.Jitter > 1 {
-		out.Jitter = 1
-	}
-	return out
-}
+// WaitForever — Shutdown без лимита (по умолчанию у вложенных супервизоров: их ограничивают лимиты детей).
+const WaitForever time.Duration = -1
 
-func (b BackoffPolicy) duration(attempt int, rnd *rand.Rand) time.Duration {
-	b = b.withDefaults()
-	// exp := Min * Factor^(attempt-1)
-	exp := float64(b.Min) * pow(b.Factor, attempt-1)
-	d := time.Duration(exp)
-	if d > b.Max {
-		d = b.Max
-	}
-	// джиттер +/- Jitter*50%
-	if b.Jitter > 0 {
-		j := (rnd.Float64()*2 - 1) * b.Jitter // [-Jitter, +Jitter]
-		delta := time.Duration(float64(d) * j)
-		d += delta
-		if d < b.Min {
-			d = b.Min
-		}
-		if d > b.Max {
-			d = b.Max
-		}
-	}
-	return d
-}
+// WorkerSpec описывает запуск единичного воркера.
+type WorkerSpec struct {
+	Name     string
+	Policy   RestartPolicy
+	Backoff  Backoff       // пауза перед рестартом (nil — backoff супервизора, см. WithBackoff)
+	Shutdown time.Duration // ожидание выхода при остановке: 0 — DefaultShutdownTimeout, WaitForever — без лимита
+	Fn       WorkerFunc
 
-func pow(a float64, n int) float64 {
-	out := 1.0
-	for i := 0; i < n; i++ {
-		out *= a
-	}
-	return out
+	nested func() *Supervisor // текущий вложенный супервизор (SupervisorSpec)
 }
 
-// WorkerSpec описывает запуск единичного воркера.
-type WorkerSpec struct {
-	Name    string
-	Policy  RestartPolicy
-	Backoff BackoffPolicy
-	Fn      WorkerFunc
+func (w WorkerSpec) shutdownTimeout() time.Duration {
+	if w.Shutdown == 0 {
+		return DefaultShutdownTimeout
+	}
+	return w.Shutdown
 }
 
 // EventType — тип события супервизора.
 type EventType string
 
 const (
-	EventStart   EventType = "start"
-	EventExit    EventType = "exit"
-	EventRestart EventType = "restart"
-	EventPanic   EventType = "panic"
-	EventStop    EventType = "stop"
+	EventStart       EventType = "start"
+	EventExit        EventType = "exit"
+	EventRestart     EventType = "restart"
+	EventPanic       EventType = "panic"
+	EventStop        EventType = "stop"
+	EventShutdown    EventType = "shutdown"     // супервизор остановился сам (Err — причина)
+	EventStopTimeout EventType = "stop_timeout" // воркер не вышел за Shutdown и брошен
+	EventSuspend     EventType = "suspend"
+	EventResume      EventType = "resume"
 )
 
 // Event — событие жизненного цикла воркера.
@@ -121,24 +83,104 @@ type Event struct {
 	NextAfter time.Duration
 }
 
-// Supervisor — надзор за воркерами с политиками рестартов.
-type Supervisor struct {
-	mu      sync.Mutex
-	ctx     context.Context
-	cancel  context.CancelFunc
-	wg      sync.WaitGroup
-	workers map[string]*worker
-	onEvent func(Event)
+// Strategy — стратегия рестарта при падении воркера (как в Erlang/OTP).
+type Strategy int
 
-	rnd *rand.Rand
+const (
+	OneForOne  Strategy = iota // перезапускается только упавший воркер
+	OneForAll                  // перезапускаются все воркеры
+	RestForOne                 // перезапускаются упавший и запущенные после него
+)
+
+func (s Strategy) String() string {
+	switch s {
+	case OneForOne:
+		return "one_for_one"
+	case OneForAll:
+		return "one_for_all"
+	case RestForOne:
+		return "rest_for_one"
+	default:
+		return "unknown"
+	}
 }
 
-type worker struct {
-	spec     WorkerSpec
+// ParseStrategy разбирает стратегию из конфига; пустая строка — OneForOne.
+func ParseStrategy(s string) (Strategy, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "one_for_one":
+		return OneForOne, nil
+	case "one_for_all":
+		return OneForAll, nil
+	case "rest_for_one":
+		return RestForOne, nil
+	default:
+		return OneForOne, fmt.Errorf("unknown supervisor strategy %q", s)
+	}
+}
+
+// IntensityError — причина самоостановки супервизора: превышен лимит рестартов.
+type IntensityError struct {
+	Limit  RestartIntensity
+	Worker string // воркер, чьё падение превысило лимит
+	Err    error  // его последняя ошибка
+}
+
+func (e *IntensityError) Error() string {
+	msg := fmt.Sprintf("restart intensity exceeded: more than %d restarts within %s (last: worker %s", e.Limit.Max, e.Limit.Window, e.Worker)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg + ")"
+}
+
+func (e *IntensityError) Unwrap() error { return e.Err }
+
+// Supervisor — надзор за воркерами с политиками рестартов, стратегией и лимитом рестартов.
+// Рестартами управляет одна горутина (loop), поэтому порядок запуска сохраняется.
+type Supervisor struct {
+	mu       sync.Mutex
 	ctx      context.Context
 	cancel   context.CancelFunc
-	attempts int
-	stopped  bool
+	workers  []*worker // в порядке запуска
+	onEvent  func(Event)
+	strategy Strategy
+	limit    RestartIntensity
+	tracker  *RestartTracker
+	exits    chan workerExit
+	cmds     chan func()
+	closing  bool
+	stopping chan struct{} // закрывается в начале остановки
+	done     chan struct{} // закрывается, когда остановка завершена
+	idle     *sync.Cond    // на mu: воркеров не осталось или супервизор остановлен (для Wait)
+	err      error
+	backoff  Backoff
+	clock    Clock
+}
+
+type worker struct {
+	spec      WorkerSpec
+	cancel    context.CancelFunc
+	done      chan struct{} // закрывается по выходу текущего запуска
+	attempts  int
+	stopped   bool
+	suspended bool          // Suspend: не перезапускать до Resume
+	manual    bool          // следующий выход — ручной рестарт
+	gen       int           // номер запланированного рестарта (устаревшие таймеры пропускаются)
+	exited    bool          // выход текущего запуска уже учтён (соседи при групповом рестарте)
+	lastSleep time.Duration // предыдущая пауза backoff (для DecorrelatedBackoff); только из loop
+
+	// для Snapshot
+	state       WorkerState
+	lastErr     error
+	startedAt   time.Time
+	nextRestart time.Time
+}
+
+type workerExit struct {
+	w    *worker
+	done chan struct{}
+	err  error
 }
 
 type SupervisorOption func(*Supervisor)
@@ -148,18 +190,50 @@ func WithOnEvent(h func(Event)) SupervisorOption {
 	return func(s *Supervisor) { s.onEvent = h }
 }
 
+// WithStrategy задаёт стратегию рестарта (по умолчанию OneForOne).
+func WithStrategy(st Strategy) SupervisorOption {
+	return func(s *Supervisor) { s.strategy = st }
+}
+
+// WithIntensity задаёт лимит рестартов (по умолчанию без лимита; Max <= 0 — без лимита).
+// При превышении супервизор останавливает все воркеры, а Err() возвращает *IntensityError.
+func WithIntensity(l RestartIntensity) SupervisorOption {
+	return func(s *Supervisor) { s.limit = l }
+}
+
+// WithBackoff задаёт backoff для воркеров без своего WorkerSpec.Backoff (по умолчанию BackoffPolicy{}).
+func WithBackoff(b Backoff) SupervisorOption {
+	return func(s *Supervisor) {
+		if b != nil {
+			s.backoff = b
+		}
+	}
+}
+
+// WithClock задаёт часы для backoff, таймаутов остановки и окна рестартов (по умолчанию SystemClock).
+func WithClock(c Clock) SupervisorOption {
+	return func(s *Supervisor) { s.clock = orSystemClock(c) }
+}
+
 // NewSupervisor создаёт новый супервизор с собственным контекстом.
 func NewSupervisor(opts ...SupervisorOption) *Supervisor {
 	ctx, cancel := context.WithCancel(context.Background())
 	s := &Supervisor{
-		ctx:     ctx,
-		cancel:  cancel,
-		workers: make(map[string]*worker),
-		rnd:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		ctx:      ctx,
+		cancel:   cancel,
+		exits:    make(chan workerExit),
+		cmds:     make(chan func()),
+		stopping: make(chan struct{}),
+		done:     make(chan struct{}),
+		backoff:  BackoffPolicy{},
+		clock:    SystemClock,
 	}
+	s.idle = sync.NewCond(&s.mu)
 	for _, o := range opts {
 		o(s)
 	}
+	s.tracker = NewRestartTracker(s.limit)
+	go s.loop()
 	return s
 }
 
@@ -177,101 +251,369 @@ func (s *Supervisor) Start(spec WorkerSpec) error {
 		return fmt.Errorf("invalid worker spec")
 	}
 	s.mu.Lock()
-	if _, exists := s.workers[spec.Name]; exists {
+	if s.closing {
+		s.mu.Unlock()
+		return ErrSupervisorStopped
+	}
+	if s.findLocked(spec.Name) != nil {
 		s.mu.Unlock()
 		return fmt.Errorf("worker %q already exists", spec.Name)
 	}
-	wctx, cancel := context.WithCancel(s.ctx)
-	w := &worker{spec: spec, ctx: wctx, cancel: cancel}
-	s.workers[spec.Name] = w
-	s.wg.Add(1)
+	w := &worker{spec: spec}
+	s.workers = append(s.workers, w)
+	ev := s.launchLocked(w)
 	s.mu.Unlock()
-
-	go s.runWorker(w)
+	s.emitAll(ev)
 	return nil
 }
 
-// Stop останавливает воркер по имени (мягкая остановка).
+// Stop останавливает воркер по имени (мягкая остановка, без рестарта).
 func (s *Supervisor) Stop(name string) {
 	s.mu.Lock()
-	w, ok := s.workers[name]
-	if ok && !w.stopped {
-		w.stopped = true
-		w.cancel()
+	w := s.findLocked(name)
+	if w == nil || w.stopped {
+		s.mu.Unlock()
+		return
 	}
+	w.stopped = true
+	if w.state != WorkerRunning {
+		// не запущен (ждёт рестарта или приостановлен) — выхода не будет, убираем сразу
+		s.removeLocked(w)
+		s.mu.Unlock()
+		s.emit(Event{Time: s.clock.Now(), Worker: name, Type: EventStop})
+		return
+	}
+	w.state = WorkerStopping
+	w.cancel()
 	s.mu.Unlock()
 }
 
-// StopAll останавливает все воркеры и сам супервизор.
-func (s *Supervisor) StopAll() {
+// StopAll останавливает воркеры в обратном порядке запуска, ожидая каждый не дольше его Shutdown,
+// затем сам супервизор. Возвращается, когда всё дерево остановлено или лимиты ожидания истекли.
+func (s *Supervisor) StopAll() { s.shutdown(nil) }
+
+// Wait ожидает завершения всех воркеров: каждый вышел без рестарта по своей политике
+// или супервизор остановлен (StopAll, превышение лимита). Остановку самого супервизора — см. Done.
+func (s *Supervisor) Wait() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.workers) > 0 && !s.stoppedLocked() {
+		s.idle.Wait()
+	}
+}
+
+func (s *Supervisor) stoppedLocked() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Done закрывается, когда супервизор остановлен (воркеры вышли или брошены по Shutdown).
+func (s *Supervisor) Done() <-chan struct{} { return s.done }
+
+// Run блокируется до отмены ctx (тогда останавливает дерево) или самоостановки супервизора
+// и возвращает Err(). Так супервизор работает воркером другого супервизора (см. SupervisorSpec).
+func (s *Supervisor) Run(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		s.StopAll()
+	case <-s.done:
+	}
+	return s.Err()
+}
+
+// SupervisorSpec описывает вложенный супервизор как воркер: на каждый запуск создаётся новый
+// супервизор с opts, а setup запускает его воркеры. Самоостановка вложенного супервизора —
+// ошибка воркера для родителя; по умолчанию Shutdown = WaitForever.
+func SupervisorSpec(name string, setup func(*Supervisor) error, opts ...SupervisorOption) WorkerSpec {
+	var cur atomic.Pointer[Supervisor]
+	return WorkerSpec{
+		Name:     name,
+		Policy:   Permanent,
+		Shutdown: WaitForever,
+		nested:   cur.Load,
+		Fn: func(ctx context.Context) error {
+			sup := NewSupervisor(opts...)
+			cur.Store(sup)
+			if err := setup(sup); err != nil {
+				sup.StopAll()
+				return err
+			}
+			return sup.Run(ctx)
+		},
+	}
+}
+
+// Err — причина самоостановки (*IntensityError); nil, если супервизор работает или остановлен StopAll.
+func (s *Supervisor) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// shutdown останавливает воркеры в обратном порядке запуска; err != nil — супервизор сдался сам.
+// Повторный вызов ждёт завершения первого.
+func (s *Supervisor) shutdown(err error) {
+	s.mu.Lock()
+	if s.closing {
+		s.mu.Unlock()
+		<-s.done
+		return
+	}
+	s.closing = true
+	s.err = err
+	close(s.stopping)
+	ws := append([]*worker(nil), s.workers...)
+	for _, w := range ws {
+		w.stopped = true
+		w.state = WorkerStopping
+	}
+	s.mu.Unlock()
+	if err != nil {
+		s.emit(Event{Time: s.clock.Now(), Type: EventShutdown, Err: err})
+	}
+	for i := len(ws) - 1; i >= 0; i-- {
+		s.awaitStop(ws[i])
+	}
 	s.cancel()
 	s.mu.Lock()
+	close(s.done)
+	s.idle.Broadcast()
+	s.mu.Unlock()
+}
+
+// awaitStop отменяет текущий запуск воркера и ждёт его выхода не дольше Shutdown.
+// Не вышедший вовремя воркер бросается (событие stop_timeout).
+func (s *Supervisor) awaitStop(w *worker) bool {
+	s.mu.Lock()
+	cancel, done, timeout := w.cancel, w.done, w.spec.shutdownTimeout()
+	w.state = WorkerStopping
+	s.mu.Unlock()
+	cancel()
+	if timeout < 0 {
+		<-done
+		return true
+	}
+	t := s.clock.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case <-done:
+		return true
+	case <-t.C():
+		s.emit(Event{Time: s.clock.Now(), Worker: w.spec.Name, Type: EventStopTimeout,
+			Err: fmt.Errorf("worker %s did not stop within %s", w.spec.Name, timeout)})
+		return false
+	}
+}
+
+func (s *Supervisor) findLocked(name string) *worker {
 	for _, w := range s.workers {
-		if !w.stopped {
-			w.stopped = true
-			w.cancel()
+		if w.spec.Name == name {
+			return w
 		}
 	}
-	s.mu.Unlock()
+	return nil
 }
 
-// Wait ожидает завершения всех воркеров.
-func (s *Supervisor) Wait() { s.wg.Wait() }
+func (s *Supervisor) removeLocked(w *worker) {
+	w.stopped = true
+	for i, x := range s.workers {
+		if x == w {
+			s.workers = append(s.workers[:i:i], s.workers[i+1:]...)
+			break
+		}
+	}
+	if len(s.workers) == 0 {
+		s.idle.Broadcast()
+	}
+}
+
+func (s *Supervisor) emitAll(evs []Event) {
+	for _, e := range evs {
+		s.emit(e)
+	}
+}
+
+// launchLocked запускает очередной экземпляр воркера; выход сообщается в loop.
+// Возвращает событие start — его отправляют после mu, чтобы обработчик мог звать супервизор.
+func (s *Supervisor) launchLocked(w *worker) []Event {
+	if s.closing {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(s.ctx)
+	done := make(chan struct{})
+	w.cancel, w.done, w.exited = cancel, done, false
+	w.state, w.startedAt, w.nextRestart = WorkerRunning, s.clock.Now(), time.Time{}
+	name, attempt, fn := w.spec.Name, w.attempts+1, w.spec.Fn
+	go func() {
+		err := s.call(ctx, name, attempt, fn)
+		cancel()
+		close(done)
+		select {
+		case s.exits <- workerExit{w: w, done: done, err: err}:
+		case <-s.ctx.Done():
+			s.emit(Event{Time: s.clock.Now(), Worker: name, Type: EventStop})
+		}
+	}()
+	return []Event{{Time: s.clock.Now(), Worker: name, Type: EventStart, Attempt: attempt}}
+}
 
-func (s *Supervisor) runWorker(w *worker) {
-	defer s.wg.Done()
-	b := w.spec.Backoff.withDefaults()
+func (s *Supervisor) call(ctx context.Context, name string, attempt int, fn WorkerFunc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+			s.emit(Event{Time: s.clock.Now(), Worker: name, Type: EventPanic, Attempt: attempt, Err: err})
+		}
+	}()
+	return fn(ctx)
+}
 
+func (s *Supervisor) loop() {
 	for {
 		select {
 		case <-s.ctx.Done():
-			s.emit(Event{Time: time.Now(), Worker: w.spec.Name, Type: EventStop})
 			return
-		default:
+		case ex := <-s.exits:
+			s.handleExit(ex)
+		case fn := <-s.cmds:
+			fn()
 		}
+	}
+}
 
-		s.emit(Event{Time: time.Now(), Worker: w.spec.Name, Type: EventStart, Attempt: w.attempts + 1})
-
-		err := func() (err error) {
-			defer func() {
-				if r := recover(); r != nil {
-					err = fmt.Errorf("panic: %v", r)
-					s.emit(Event{Time: time.Now(), Worker: w.spec.Name, Type: EventPanic, Attempt: w.attempts + 1, Err: err})
-				}
-			}()
-			return w.spec.Fn(w.ctx)
-		}()
-
-		w.attempts++
-		s.emit(Event{Time: time.Now(), Worker: w.spec.Name, Type: EventExit, Attempt: w.attempts, Err: err})
-
-		// Решение о рестарте по политике
-		restart := false
-		switch w.spec.Policy {
-		case Permanent:
-			restart = true
-		case Transient:
-			restart = err != nil
-		case Temporary:
-			restart = false
+// groupLocked — воркеры, которые перезапускаются вместе с w по стратегии (в порядке запуска).
+func (s *Supervisor) groupLocked(w *worker) []*worker {
+	switch s.strategy {
+	case OneForAll:
+		return append([]*worker(nil), s.workers...)
+	case RestForOne:
+		for i, x := range s.workers {
+			if x == w {
+				return append([]*worker(nil), s.workers[i:]...)
+			}
 		}
+	}
+	return []*worker{w}
+}
 
-		if !restart {
-			// завершить и удалить
-			s.mu.Lock()
-			delete(s.workers, w.spec.Name)
-			s.mu.Unlock()
-			return
+func (s *Supervisor) handleExit(ex workerExit) {
+	w := ex.w
+	s.mu.Lock()
+	if w.done != ex.done || w.exited {
+		// устаревший запуск: воркер уже перезапущен или ждёт рестарта вместе с группой
+		s.mu.Unlock()
+		return
+	}
+	if w.stopped {
+		s.removeLocked(w)
+		s.mu.Unlock()
+		s.emit(Event{Time: s.clock.Now(), Worker: w.spec.Name, Type: EventStop})
+		return
+	}
+	if w.manual {
+		// ручной рестарт (Restart/Resume/Replace): сразу, без backoff и учёта в лимите
+		w.manual = false
+		started := s.launchLocked(w)
+		s.mu.Unlock()
+		s.emit(Event{Time: s.clock.Now(), Worker: w.spec.Name, Type: EventRestart, Attempt: w.attempts, Err: ex.err})
+		s.emitAll(started)
+		return
+	}
+	if w.suspended {
+		w.state = WorkerSuspended
+		s.mu.Unlock()
+		s.emit(Event{Time: s.clock.Now(), Worker: w.spec.Name, Type: EventSuspend, Err: ex.err})
+		return
+	}
+	w.attempts++
+	attempt := w.attempts
+	if ex.err != nil {
+		w.lastErr = ex.err
+	}
+	s.mu.Unlock()
+	s.emit(Event{Time: s.clock.Now(), Worker: w.spec.Name, Type: EventExit, Attempt: attempt, Err: ex.err})
+
+	s.mu.Lock()
+	if !w.spec.Policy.ShouldRestart(ex.err != nil) {
+		s.removeLocked(w)
+		s.mu.Unlock()
+		return
+	}
+	group := s.groupLocked(w)
+	backoff := w.spec.Backoff
+	if backoff == nil {
+		backoff = s.backoff
+	}
+	s.mu.Unlock()
+
+	if s.tracker.Record(s.clock.Now()) {
+		s.shutdown(&IntensityError{Limit: s.limit, Worker: w.spec.Name, Err: ex.err})
+		return
+	}
+
+	// остальные участники группы останавливаются в обратном порядке запуска
+	for i := len(group) - 1; i >= 0; i-- {
+		if g := group[i]; g != w {
+			s.awaitStop(g)
 		}
+	}
 
-		// backoff + jitter
-		sleep := b.duration(w.attempts, s.rnd)
-		s.emit(Event{Time: time.Now(), Worker: w.spec.Name, Type: EventRestart, Attempt: w.attempts, Err: err, NextAfter: sleep})
+	// рестарт по таймеру, loop тем временем обслуживает остальных и ручные команды
+	sleep := backoff.Next(attempt, w.lastSleep)
+	w.lastSleep = sleep
+	next := s.clock.Now().Add(sleep)
+	gens := make([]int, len(group))
+	s.mu.Lock()
+	for i, g := range group {
+		g.gen++
+		gens[i] = g.gen
+		g.exited = true
+		if !g.stopped && !g.suspended {
+			g.state, g.nextRestart = WorkerRestarting, next
+		}
+	}
+	s.mu.Unlock()
+	for _, g := range group {
+		e := Event{Time: s.clock.Now(), Worker: g.spec.Name, Type: EventRestart, Attempt: g.attempts, NextAfter: sleep}
+		if g == w {
+			e.Err = ex.err
+		}
+		s.emit(e)
+	}
+	s.clock.AfterFunc(sleep, func() {
+		s.post(func() { s.relaunch(w, group, gens) })
+	})
+}
 
-		select {
-		case <-time.After(sleep):
-		case <-s.ctx.Done():
-			return
+// relaunch запускает группу после backoff. Воркеры, которые за это время остановили,
+// приостановили или перезапустили вручную (сменился gen), пропускаются.
+func (s *Supervisor) relaunch(w *worker, group []*worker, gens []int) {
+	var started []Event
+	s.mu.Lock()
+	for i, g := range group {
+		switch {
+		case g.gen != gens[i]:
+			continue
+		case g.stopped || g != w && g.spec.Policy == Temporary:
+			// явно остановленные и Temporary-соседи не перезапускаются
+			s.removeLocked(g)
+		case g.suspended:
+			g.state = WorkerSuspended
+		default:
+			started = append(started, s.launchLocked(g)...)
 		}
 	}
+	s.mu.Unlock()
+	s.emitAll(started)
+}
+
+// post выполняет fn в горутине loop (если супервизор ещё не останавливается).
+func (s *Supervisor) post(fn func()) {
+	select {
+	case s.cmds <- fn:
+	case <-s.stopping:
+	}
 }
@@ -0,0 +1,162 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSupervisorStrategies(t *testing.T) {
+	tests := []struct {
+		strategy Strategy
+		want     [3]int64 // запуски a, b, c после падения b
+	}{
+		{OneForOne, [3]int64{1, 2, 1}},
+		{OneForAll, [3]int64{2, 2, 2}},
+		{RestForOne, [3]int64{1, 2, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.strategy.String(), func(t *testing.T) {
+			clock := NewFakeClock(time.Time{})
+			restarted := make(chan string, 16)
+			s := NewSupervisor(WithStrategy(tt.strategy), WithClock(clock),
+				WithBackoff(ConstantBackoff{Delay: time.Second}),
+				WithOnEvent(func(e Event) {
+					if e.Type == EventRestart {
+						restarted <- e.Worker
+					}
+				}))
+			defer s.StopAll()
+
+			var starts [3]atomic.Int64
+			crash := make(chan struct{})
+			for i, name := range []string{"a", "b", "c"} {
+				i, name := i, name
+				err := s.Start(WorkerSpec{Name: name, Policy: Permanent, Fn: func(ctx context.Context) error {
+					// падает только первый запуск b
+					if starts[i].Add(1) == 1 && name == "b" {
+						select {
+						case <-ctx.Done():
+						case <-crash:
+							return errors.New("boom")
+						}
+					}
+					<-ctx.Done()
+					return nil
+				}})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			eventually(t, "workers started", func() bool {
+				return starts[0].Load() == 1 && starts[1].Load() == 1 && starts[2].Load() == 1
+			})
+
+			close(crash)
+			<-restarted
+			clock.BlockUntil(1)
+			clock.Advance(time.Second)
+
+			var total int64
+			for _, n := range tt.want {
+				total += n
+			}
+			eventually(t, "restart", func() bool {
+				return starts[0].Load()+starts[1].Load()+starts[2].Load() == total
+			})
+			for i := range starts {
+				if got := starts[i].Load(); got != tt.want[i] {
+					t.Fatalf("worker %d started %d times, want %d", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSupervisorIntensity(t *testing.T) {
+	tests := []struct {
+		name     string
+		limit    RestartIntensity
+		crashes  int
+		wantStop bool
+	}{
+		{"unlimited by default", RestartIntensity{}, 6, false},
+		{"exceeded within window", RestartIntensity{Max: 2, Window: time.Minute}, 3, true},
+		{"window slides", RestartIntensity{Max: 2, Window: 1500 * time.Millisecond}, 6, false},
+		{"no window counts all", RestartIntensity{Max: 4}, 5, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := NewFakeClock(time.Time{})
+			s := NewSupervisor(WithIntensity(tt.limit), WithClock(clock),
+				WithBackoff(ConstantBackoff{Delay: time.Second}))
+			defer s.StopAll()
+
+			var crashes atomic.Int64
+			if err := s.Start(WorkerSpec{Name: "w", Policy: Permanent, Fn: func(context.Context) error {
+				crashes.Add(1)
+				return errors.New("boom")
+			}}); err != nil {
+				t.Fatal(err)
+			}
+			// каждый рестарт ждёт backoff на FakeClock; лимит проверяется только при падении
+			eventually(t, "first crash", func() bool { return crashes.Load() == 1 })
+			for n := int64(1); n < int64(tt.crashes); n++ {
+				clock.BlockUntil(1)
+				clock.Advance(time.Second)
+				eventually(t, "relaunch", func() bool { return crashes.Load() == n+1 })
+			}
+			if !tt.wantStop {
+				clock.BlockUntil(1) // следующий рестарт запланирован
+				if isDone(s) {
+					t.Fatalf("supervisor stopped after %d crashes: %v", crashes.Load(), s.Err())
+				}
+				return
+			}
+			eventually(t, "supervisor stopped", func() bool { return isDone(s) })
+			if got := crashes.Load(); got != int64(tt.crashes) {
+				t.Fatalf("stopped after %d crashes, want %d", got, tt.crashes)
+			}
+			var ie *IntensityError
+			if !errors.As(s.Err(), &ie) || ie.Worker != "w" || ie.Limit != tt.limit {
+				t.Fatalf("Err() = %v, want *IntensityError for w", s.Err())
+			}
+		})
+	}
+}
+
+func TestRestartTrackerRecord(t *testing.T) {
+	t0 := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		limit RestartIntensity
+		at    []time.Duration // моменты рестартов от t0
+		want  []bool
+	}{
+		{"unlimited", RestartIntensity{}, []time.Duration{0, 0, 0}, []bool{false, false, false}},
+		{"burst", RestartIntensity{Max: 2, Window: time.Minute}, []time.Duration{0, time.Second, 2 * time.Second}, []bool{false, false, true}},
+		{"spread", RestartIntensity{Max: 1, Window: time.Second}, []time.Duration{0, 2 * time.Second, 4 * time.Second}, []bool{false, false, false}},
+		{"window edge counts", RestartIntensity{Max: 1, Window: time.Second}, []time.Duration{0, time.Second}, []bool{false, true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := NewRestartTracker(tt.limit)
+			for i, d := range tt.at {
+				if got := tr.Record(t0.Add(d)); got != tt.want[i] {
+					t.Fatalf("Record #%d at +%s = %v, want %v", i+1, d, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func isDone(s *Supervisor) bool {
+	select {
+	case <-s.Done():
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,84 @@
+package runtime
+
+import "time"
+
+// WorkerState — состояние воркера в Snapshot.
+type WorkerState string
+
+const (
+	WorkerRunning    WorkerState = "running"
+	WorkerRestarting WorkerState = "restarting" // ждёт backoff перед рестартом
+	WorkerStopping   WorkerState = "stopping"
+)
+
+// WorkerInfo — снимок воркера супервизора.
+type WorkerInfo struct {
+	Name        string              `json:"name"`
+	Policy      string              `json:"policy"`
+	State       WorkerState         `json:"state"`
+	Attempts    int                 `json:"attempts"`
+	LastError   string              `json:"last_error,omitempty"`
+	StartedAt   time.Time           `json:"started_at"`
+	NextRestart *time.Time          `json:"next_restart,omitempty"`
+	Supervisor  *SupervisorSnapshot `json:"supervisor,omitempty"` // вложенный супервизор (SupervisorSpec)
+}
+
+// SupervisorSnapshot — снимок супервизора и его воркеров в порядке запуска.
+type SupervisorSnapshot struct {
+	Strategy      string       `json:"strategy"`
+	MaxRestarts   int          `json:"max_restarts"`
+	RestartWindow string       `json:"restart_window"`
+	Restarts      int          `json:"restarts"` // рестартов в текущем окне
+	Stopped       bool         `json:"stopped"`
+	Err           string       `json:"error,omitempty"`
+	Workers       []WorkerInfo `json:"workers"`
+}
+
+// Snapshot возвращает состояние супервизора и воркеров (рекурсивно для вложенных).
+func (s *Supervisor) Snapshot() SupervisorSnapshot {
+	now := s.clock.Now()
+	s.mu.Lock()
+	snap := SupervisorSnapshot{
+		Strategy:      s.strategy.String(),
+		MaxRestarts:   s.limit.Max,
+		RestartWindow: s.limit.Window.String(),
+		Stopped:       s.closing,
+		Workers:       make([]WorkerInfo, 0, len(s.workers)),
+	}
+	if s.err != nil {
+		snap.Err = s.err.Error()
+	}
+	var nested []func() *Supervisor
+	for _, w := range s.workers {
+		info := WorkerInfo{
+			Name:      w.spec.Name,
+			Policy:    w.spec.Policy.String(),
+			State:     w.state,
+			Attempts:  w.attempts,
+			StartedAt: w.startedAt,
+		}
+		if w.lastErr != nil {
+			info.LastError = w.lastErr.Error()
+		}
+		if w.state == WorkerRestarting {
+			next := w.nextRestart
+			info.NextRestart = &next
+		}
+		snap.Workers = append(snap.Workers, info)
+		nested = append(nested, w.spec.nested)
+	}
+	s.mu.Unlock()
+	snap.Restarts = s.tracker.Count(now)
+
+	// вложенные снимаются вне mu: у них свои блокировки
+	for i, f := range nested {
+		if f == nil || snap.Workers[i].State != WorkerRunning {
+			continue
+		}
+		if sub := f(); sub != nil {
+			ss := sub.Snapshot()
+			snap.Workers[i].Supervisor = &ss
+		}
+	}
+	return snap
+}
@@ -0,0 +1,282 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"example.com/ffp/platform/contracts"
+	"example.com/ffp/platform/ports"
+)
+
+// TopicStrictness — как хост относится к темам, которых нет в Exports/Imports.Events ядра.
+type TopicStrictness string
+
+const (
+	TopicsOff     TopicStrictness = "off"     // не проверять
+	TopicsWarn    TopicStrictness = "warn"    // пропускать, считать, WARN при первом нарушении по теме
+	TopicsEnforce TopicStrictness = "enforce" // отказывать с ErrTopicNotDeclared и считать
+)
+
+// ParseTopicStrictness разбирает строгость из конфига ("" — warn).
+func ParseTopicStrictness(s string) (TopicStrictness, error) {
+	switch v := TopicStrictness(strings.ToLower(strings.TrimSpace(s))); v {
+	case "":
+		return TopicsWarn, nil
+	case TopicsOff, TopicsWarn, TopicsEnforce:
+		return v, nil
+	default:
+		return "", fmt.Errorf("unknown topic strictness %q (want off|warn|enforce)", s)
+	}
+}
+
+// ErrTopicNotDeclared — тема не объявлена в контракте ядра (строгость enforce).
+var ErrTopicNotDeclared = errors.New("topic not declared in kernel contract")
+
+// ContractDeclarer — ядро объявляет события, которые публикует (Exports.Events) и слушает (Imports.Events).
+// Объявления читаются при каждой проверке, поэтому темы могут зависеть от конфига ядра.
+type ContractDeclarer interface {
+	Exports() *contracts.Exports
+	Imports() *contracts.Imports
+}
+
+// DeclaredTopics — источник тем ядра для TopicGuard.View; ядро без ContractDeclarer не объявляет ничего.
+func DeclaredTopics(k KernelModule) func() (exports, imports []string) {
+	d, ok := k.(ContractDeclarer)
+	if !ok {
+		return func() ([]string, []string) { return nil, nil }
+	}
+	return func() ([]string, []string) { return BridgeTopics(d.Exports(), d.Imports()) }
+}
+
+// TopicViolation — обращения ядра к одной необъявленной теме.
+type TopicViolation struct {
+	Op    string    `json:"op"` // publish | subscribe
+	Topic string    `json:"topic"`
+	Count uint64    `json:"count"`
+	Last  time.Time `json:"last"`
+}
+
+// KernelTopicReport — контракт ядра и его нарушения (GET /admin/bus/contracts).
+type KernelTopicReport struct {
+	Kernel     string           `json:"kernel"`
+	Strictness TopicStrictness  `json:"strictness"`
+	Exports    []string         `json:"exports"`
+	Imports    []string         `json:"imports"`
+	Violations uint64           `json:"violations"`
+	Topics     []TopicViolation `json:"topics,omitempty"`
+}
+
+type guardKernel struct {
+	strict   TopicStrictness
+	declared func() (exports, imports []string)
+	total    uint64
+	topics   map[string]*TopicViolation // op + " " + topic
+}
+
+// TopicGuard выдаёт ядрам виды шины (BusView), проверяющие их контракт, и копит нарушения по ядрам.
+type TopicGuard struct {
+	bus    ports.EventBus
+	strict TopicStrictness
+	logger ports.Logger
+
+	mu      sync.Mutex
+	kernels map[string]*guardKernel
+}
+
+// NewTopicGuard создаёт охрану над шиной bus со строгостью по умолчанию strict.
+func NewTopicGuard(bus ports.EventBus, strict TopicStrictness, logger ports.Logger) *TopicGuard {
+	if strict == "" {
+		strict = TopicsWarn
+	}
+	return &TopicGuard{bus: bus, strict: strict, logger: logger, kernels: make(map[string]*guardKernel)}
+}
+
+// View — шина ядра kernel: публикация только в exports, подписка только на imports
+// (с точностью до шаблонов). strict "" — строгость охраны. Повторный View того же ядра
+// заменяет контракт, накопленные нарушения сохраняются.
+func (g *TopicGuard) View(kernel string, declared func() (exports, imports []string), strict TopicStrictness) *BusView {
+	if strict == "" {
+		strict = g.strict
+	}
+	if declared == nil {
+		declared = func() ([]string, []string) { return nil, nil }
+	}
+	g.mu.Lock()
+	k := g.kernels[kernel]
+	if k == nil {
+		k = &guardKernel{topics: make(map[string]*TopicViolation)}
+		g.kernels[kernel] = k
+	}
+	k.strict, k.declared = strict, declared
+	g.mu.Unlock()
+	return &BusView{guard: g, kernel: kernel, strict: strict, declared: declared}
+}
+
+// Record учитывает нарушение, пойманное вне BusView (например, мостом process-домена).
+func (g *TopicGuard) Record(kernel, op, topic string) {
+	g.mu.Lock()
+	k := g.kernels[kernel]
+	if k == nil {
+		k = &guardKernel{strict: g.strict, topics: make(map[string]*TopicViolation)}
+		g.kernels[kernel] = k
+	}
+	k.total++
+	key := op + " " + topic
+	v := k.topics[key]
+	first := v == nil
+	if first {
+		v = &TopicViolation{Op: op, Topic: topic}
+		k.topics[key] = v
+	}
+	v.Count++
+	v.Last = time.Now()
+	strict := k.strict
+	g.mu.Unlock()
+
+	if first && g.logger != nil {
+		level := "WARN"
+		if strict == TopicsEnforce {
+			level = "ERROR"
+		}
+		g.logger.Log(context.Background(), level, "topic not declared in kernel contract", map[string]any{
+			"kernel": kernel, "op": op, "topic": topic, "strictness": string(strict),
+		})
+	}
+}
+
+// Report — контракты и нарушения по ядрам (по id).
+func (g *TopicGuard) Report() []KernelTopicReport {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	out := make([]KernelTopicReport, 0, len(g.kernels))
+	for id, k := range g.kernels {
+		r := KernelTopicReport{Kernel: id, Strictness: k.strict, Violations: k.total}
+		if k.declared != nil {
+			r.Exports, r.Imports = k.declared()
+		}
+		if r.Exports == nil {
+			r.Exports = []string{}
+		}
+		if r.Imports == nil {
+			r.Imports = []string{}
+		}
+		for _, v := range k.topics {
+			r.Topics = append(r.Topics, *v)
+		}
+		sort.Slice(r.Topics, func(i, j int) bool {
+			if r.Topics[i].Topic != r.Topics[j].Topic {
+				return r.Topics[i].Topic < r.Topics[j].Topic
+			}
+			return r.Topics[i].Op < r.Topics[j].Op
+		})
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Kernel < out[j].Kernel })
+	return out
+}
+
+// BusView — шина ядра, проверяющая темы по его контракту. Темы ответов (ports.InboxPrefix)
+// не проверяются: это служебные темы запрос/ответ.
+type BusView struct {
+	guard    *TopicGuard
+	kernel   string
+	strict   TopicStrictness
+	declared func() (exports, imports []string)
+}
+
+var (
+	_ ports.EventBus          = (*BusView)(nil)
+	_ ports.MultiSubscriber   = (*BusView)(nil)
+	_ ports.SubscriberCounter = (*BusView)(nil)
+	_ ports.ResponderCounter  = (*BusView)(nil)
+)
+
+// Unwrap — шина под видом (для служебных публикаций хоста, например логов).
+func (v *BusView) Unwrap() ports.EventBus { return v.guard.bus }
+
+// Guard — охрана, выдавшая вид; через неё получают виды для дочерних ядер.
+func (v *BusView) Guard() *TopicGuard { return v.guard }
+
+// Strictness — строгость вида.
+func (v *BusView) Strictness() TopicStrictness { return v.strict }
+
+func (v *BusView) Publish(ctx context.Context, topic string, msg any) error {
+	if err := v.check("publish", topic); err != nil {
+		return err
+	}
+	return v.guard.bus.Publish(ctx, topic, msg)
+}
+
+func (v *BusView) Subscribe(ctx context.Context, topic string, opts ...ports.SubscribeOption) (<-chan any, func(), error) {
+	if err := v.check("subscribe", topic); err != nil {
+		return nil, nil, err
+	}
+	return v.guard.bus.Subscribe(ctx, topic, opts...)
+}
+
+func (v *BusView) SubscribeAll(ctx context.Context, patterns []string, opts ...ports.SubscribeOption) (<-chan any, func(), error) {
+	for _, p := range patterns {
+		if err := v.check("subscribe", p); err != nil {
+			return nil, nil, err
+		}
+	}
+	return ports.SubscribeAll(ctx, v.guard.bus, patterns, opts...)
+}
+
+// Subscribers пробрасывает ports.SubscriberCounter шины (без него — -1: неизвестно).
+func (v *BusView) Subscribers(topic string) int {
+	if sc, ok := v.guard.bus.(ports.SubscriberCounter); ok {
+		return sc.Subscribers(topic)
+	}
+	return -1
+}
+
+// Responders пробрасывает ports.ResponderCounter шины (без него — -1: неизвестно).
+func (v *BusView) Responders(topic string) int {
+	if rc, ok := v.guard.bus.(ports.ResponderCounter); ok {
+		return rc.Responders(topic)
+	}
+	return -1
+}
+
+// check: публиковать можно в тему под шаблоном exports, подписываться — шаблоном, целиком
+// покрытым одним из imports. Конкретный inbox (запрос/ответ) свободен, а шаблон вроде "_inbox.>"
+// подслушал бы чужие ответы — он проверяется как обычная тема.
+func (v *BusView) check(op, topic string) error {
+	if v.strict == TopicsOff || strings.HasPrefix(topic, ports.InboxPrefix) && !ports.IsWildcard(topic) {
+		return nil
+	}
+	exports, imports := v.declared()
+	allowed := false
+	if op == "publish" {
+		allowed = matchAny(exports, topic)
+	} else {
+		for _, p := range imports {
+			if ports.PatternCovers(p, topic) {
+				allowed = true
+				break
+			}
+		}
+	}
+	if allowed {
+		return nil
+	}
+	v.guard.Record(v.kernel, op, topic)
+	if v.strict == TopicsEnforce {
+		return fmt.Errorf("%s %s by %s: %w", op, topic, v.kernel, ErrTopicNotDeclared)
+	}
+	return nil
+}
+
+// UnguardedBus снимает BusView, если он есть.
+func UnguardedBus(b ports.EventBus) ports.EventBus {
+	if v, ok := b.(*BusView); ok {
+		return v.Unwrap()
+	}
+	return b
+}
@@ -0,0 +1,116 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// recordLogger запоминает уровни записанных сообщений.
+type recordLogger struct {
+	mu     sync.Mutex
+	levels []string
+}
+
+func (l *recordLogger) Log(_ context.Context, level, _ string, _ map[string]any) {
+	l.mu.Lock()
+	l.levels = append(l.levels, level)
+	l.mu.Unlock()
+}
+
+func TestBusViewCheck(t *testing.T) {
+	declared := func() ([]string, []string) {
+		return []string{"orders.created", "orders.*.v1"}, []string{"prices.>", "config.revision.site"}
+	}
+	tests := []struct {
+		name     string
+		op       string // publish | subscribe
+		topic    string
+		declared bool
+	}{
+		{"publish exact export", "publish", "orders.created", true},
+		{"publish under export pattern", "publish", "orders.paid.v1", true},
+		{"publish undeclared", "publish", "orders.deleted", false},
+		{"publish into import", "publish", "prices.eur", false},
+		{"publish reply inbox", "publish", "_inbox.42", true},
+		{"subscribe own inbox", "subscribe", "_inbox.42", true},
+		{"subscribe all inboxes", "subscribe", "_inbox.>", false},
+		{"subscribe inbox pattern", "subscribe", "_inbox.*", false},
+		{"subscribe exact import", "subscribe", "config.revision.site", true},
+		{"subscribe under import", "subscribe", "prices.eur.spot", true},
+		{"subscribe covered pattern", "subscribe", "prices.*", true},
+		{"subscribe same pattern", "subscribe", "prices.>", true},
+		{"subscribe wider pattern", "subscribe", ">", false},
+		{"subscribe sibling pattern", "subscribe", "config.revision.*", false},
+		{"subscribe export", "subscribe", "orders.created", false},
+	}
+	for _, strict := range []TopicStrictness{TopicsOff, TopicsWarn, TopicsEnforce} {
+		for _, tt := range tests {
+			t.Run(string(strict)+"/"+tt.name, func(t *testing.T) {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				log := &recordLogger{}
+				g := NewTopicGuard(NewInMemoryEventBus(), TopicsWarn, log)
+				v := g.View("k", declared, strict)
+
+				var err error
+				if tt.op == "publish" {
+					err = v.Publish(ctx, tt.topic, 1)
+				} else {
+					_, _, err = v.Subscribe(ctx, tt.topic)
+				}
+
+				violation := !tt.declared && strict != TopicsOff
+				wantErr := violation && strict == TopicsEnforce
+				if (err != nil) != wantErr || wantErr && !errors.Is(err, ErrTopicNotDeclared) {
+					t.Fatalf("%s %s: err = %v, want ErrTopicNotDeclared %v", tt.op, tt.topic, err, wantErr)
+				}
+				var violations uint64
+				for _, r := range g.Report() {
+					if r.Kernel == "k" {
+						violations = r.Violations
+					}
+				}
+				var want uint64
+				if violation {
+					want = 1
+				}
+				if violations != want {
+					t.Fatalf("violations = %d, want %d", violations, want)
+				}
+				wantLog := map[TopicStrictness]string{TopicsWarn: "WARN", TopicsEnforce: "ERROR"}[strict]
+				if violation && (len(log.levels) != 1 || log.levels[0] != wantLog) {
+					t.Fatalf("logged %v, want one %s", log.levels, wantLog)
+				}
+				if !violation && len(log.levels) != 0 {
+					t.Fatalf("logged %v for a declared topic", log.levels)
+				}
+			})
+		}
+	}
+}
+
+// Повторные нарушения по той же теме считаются, но пишутся в лог один раз.
+func TestTopicGuardRecordOnce(t *testing.T) {
+	log := &recordLogger{}
+	g := NewTopicGuard(NewInMemoryEventBus(), TopicsWarn, log)
+	v := g.View("k", nil, "")
+	for i := 0; i < 3; i++ {
+		if err := v.Publish(context.Background(), "orders.deleted", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = v.Publish(context.Background(), "orders.archived", 0)
+
+	r := g.Report()
+	if len(r) != 1 || r[0].Strictness != TopicsWarn || r[0].Violations != 4 || len(r[0].Topics) != 2 {
+		t.Fatalf("report = %+v, want 4 violations on 2 topics", r)
+	}
+	if r[0].Topics[1].Topic != "orders.deleted" || r[0].Topics[1].Count != 3 {
+		t.Fatalf("topic = %+v, want orders.deleted x3", r[0].Topics[1])
+	}
+	if len(log.levels) != 2 {
+		t.Fatalf("logged %d times, want 2", len(log.levels))
+	}
+}
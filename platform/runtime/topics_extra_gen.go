@@ -3,4 +3,10 @@ package runtime
 const (
 	TopicTelemetryLogsDomain   = "telemetry.logs.domain"
 	TopicTelemetryLogsFunction = "telemetry.logs.function"
+
+	// TopicTelemetryLogsScoped — шаблон всех scope-тем логов (telemetry.logs.<scope>).
+	TopicTelemetryLogsScoped = "telemetry.logs.*"
+
+	// TopicTelemetryHealthRoot — сводка здоровья узла (retained: новая подписка сразу получает текущую).
+	TopicTelemetryHealthRoot = "telemetry.health.root"
 )
@@ -0,0 +1,81 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// ParseLogLine превращает строку stdout/stderr дочернего ядра в LogRecordV2.
+// Строка-JSON с ключами level/message/component/trace/fields разбирается по полям
+// (прочие ключи попадают в Fields), любая другая строка становится сообщением как есть.
+// KernelID и Scope всегда берутся из аргументов — их назначает Root, а не процесс.
+func ParseLogLine(kernelID, scope, origin, line string, t time.Time) LogRecordV2 {
+	rec := LogRecordV2{
+		Time:      t,
+		Level:     Info,
+		KernelID:  kernelID,
+		Scope:     scope,
+		Component: "process/" + origin,
+		Message:   line,
+	}
+	if rec.Time.IsZero() {
+		rec.Time = time.Now()
+	}
+	if origin == "stderr" {
+		rec.Level = Warn
+	}
+
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "{") {
+		return rec
+	}
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(trimmed), &obj); err != nil {
+		return rec
+	}
+	msg, ok := obj["message"].(string)
+	if !ok {
+		if msg, ok = obj["msg"].(string); !ok {
+			return rec
+		}
+	}
+	rec.Message = msg
+
+	fields := map[string]any{}
+	for k, v := range obj {
+		switch k {
+		case "message", "msg":
+		case "level":
+			if s, ok := v.(string); ok {
+				rec.Level = parseLevel(s)
+			}
+		case "component":
+			if s, ok := v.(string); ok && s != "" {
+				rec.Component = s
+			}
+		case "trace":
+			if s, ok := v.(string); ok {
+				rec.Trace = s
+			}
+		case "time":
+			if s, ok := v.(string); ok {
+				if ts, err := time.Parse(time.RFC3339Nano, s); err == nil {
+					rec.Time = ts
+				}
+			}
+		case "fields":
+			if m, ok := v.(map[string]any); ok {
+				for fk, fv := range m {
+					fields[fk] = fv
+				}
+			}
+		default:
+			fields[k] = v
+		}
+	}
+	if len(fields) > 0 {
+		rec.Fields = fields
+	}
+	return rec
+}
@@ -38,6 +38,27 @@ func WithRateLimit(maxPerInterval int, interval time.Duration) SafeLogHubOption
 	}
 }
 
+// Clock — часы окна rate-limit. Подходит rt.Clock (и rt.FakeClock для тестов);
+// своя узкая копия — потому что runtime сам зависит от telemetry через ports.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// WithClock задаёт часы окна rate-limit (по умолчанию системные).
+func WithClock(c Clock) SafeLogHubOption {
+	return func(h *SafeLogHub) {
+		if c != nil {
+			h.clock = c
+		}
+	}
+}
+
 // SafeLogHub — защищённый хаб логов (очередь + rate-limit + счётчики).
 type SafeLogHub struct {
 	pub PublisherFunc
@@ -51,8 +72,8 @@ type SafeLogHub struct {
 	rateWindow time.Duration
 	rateNow    int64
 	rateMu     sync.Mutex
-	rateTick   *time.Ticker
 	lastReset  time.Time
+	clock      Clock
 
 	// очередь
 	in chan LogRecordV2
@@ -74,14 +95,14 @@ func NewSafeLogHub(pub PublisherFunc, opts ...SafeLogHubOption) *SafeLogHub {
 		buf:        1024,
 		rateMax:    1000,
 		rateWindow: time.Second,
+		clock:      systemClock{},
 		stop:       make(chan struct{}),
 	}
 	for _, o := range opts {
 		o(h)
 	}
 	h.in = make(chan LogRecordV2, h.buf)
-	h.rateTick = time.NewTicker(h.rateWindow)
-	h.lastReset = time.Now()
+	h.lastReset = h.clock.Now()
 
 	h.wg.Add(1)
 	go h.loop()
@@ -90,13 +111,15 @@ func NewSafeLogHub(pub PublisherFunc, opts ...SafeLogHubOption) *SafeLogHub {
 
 func (h *SafeLogHub) loop() {
 	defer h.wg.Done()
+	reset := h.clock.After(h.rateWindow)
 	for {
 		select {
 		case <-h.stop:
 			return
-		case <-h.rateTick.C:
+		case <-reset:
 			atomic.StoreInt64(&h.rateNow, 0)
-			h.lastReset = time.Now()
+			h.lastReset = h.clock.Now()
+			reset = h.clock.After(h.rateWindow)
 		case rec := <-h.in:
 			h.forward(rec)
 		}
@@ -160,5 +183,4 @@ func (h *SafeLogHub) Stats() LogHubStats {
 func (h *SafeLogHub) Close() {
 	close(h.stop)
 	h.wg.Wait()
-	h.rateTick.Stop()
 }
@@ -0,0 +1,125 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type kernelRec struct {
+	ID       string `json:"id"`
+	Manifest struct {
+		KernelID string `json:"kernel_id"`
+		Version  string `json:"version"`
+	} `json:"manifest"`
+	Health struct {
+		Status string `json:"status"`
+		Reason string `json:"reason"`
+	} `json:"health"`
+	Exports *struct {
+		Network []struct {
+			Name    string `json:"name"`
+			Address string `json:"address"`
+		} `json:"network"`
+	} `json:"exports"`
+}
+
+func TestRemoteDomain_AttachAndDegrade(t *testing.T) {
+	// удалённое ядро-заглушка: отдаёт hello, пока up=true
+	var up atomic.Bool
+	up.Store(true)
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rk/kernel" || !up.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"type":"hello",
+			"manifest":{"kernel_id":"billing","version":"1.2.0","scope":"domain"},
+			"exports":{"network":[{"name":"api","protocol":"http","address":"10.0.0.5:8082"}]},
+			"health":{"status":"ready"}}`))
+	}))
+	defer remote.Close()
+
+	cfg := `
+root: { node_id: "rk-1", zone: "dc-1" }
+admin: { addr: ":8090", grpc_addr: ":8079" }
+telemetry: { level: "INFO", buffer: 256 }
+domains:
+  - id: "billing"
+    mode: "remote"
+    entry: "` + remote.URL + `"
+    poll_interval: 200ms
+    fail_after: 1s
+`
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "rk.yaml")
+	if err := os.WriteFile(cfgPath, []byte(cfg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "go", "run", "-tags", "rk_run", "./cmd/rk", "-config", cfgPath)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = cmd.Process.Kill() }()
+
+	billing := func() (kernelRec, bool) {
+		resp, err := http.Get("http://localhost:8090/admin/kernels")
+		if err != nil {
+			return kernelRec{}, false
+		}
+		defer resp.Body.Close()
+		var recs []kernelRec
+		if err := json.NewDecoder(resp.Body).Decode(&recs); err != nil {
+			return kernelRec{}, false
+		}
+		for _, rec := range recs {
+			if rec.ID == "billing" {
+				return rec, true
+			}
+		}
+		return kernelRec{}, false
+	}
+	waitStatus := func(status string, within time.Duration) kernelRec {
+		deadline := time.Now().Add(within)
+		for time.Now().Before(deadline) {
+			if rec, ok := billing(); ok && rec.Health.Status == status {
+				return rec
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		rec, _ := billing()
+		t.Fatalf("billing: want status %s, got %+v", status, rec.Health)
+		return rec
+	}
+
+	// 1) удалённое ядро зарегистрировано с манифестом и экспортами
+	rec := waitStatus("ready", 20*time.Second)
+	if rec.Manifest.Version != "1.2.0" || rec.Exports == nil || len(rec.Exports.Network) != 1 {
+		t.Fatalf("unexpected record: %+v", rec)
+	}
+
+	// 2) ядро перестало отвечать: сначала degraded, после fail_after — failed
+	up.Store(false)
+	waitStatus("degraded", 2*time.Second)
+	waitStatus("failed", 3*time.Second)
+
+	// 3) ядро вернулось — снова ready
+	up.Store(true)
+	waitStatus("ready", 2*time.Second)
+}